@@ -0,0 +1,64 @@
+// Package adapter maps a gpool.Pool to the acquire and release lifecycle
+// that connection-consuming framework code expects.
+//
+// The method set mirrors resource pools that hand out connections per operation:
+// Acquire takes a context and can fail, like the Connect method of
+// database/sql/driver.Connector, Release returns the connection and Close tears the pool down.
+// Liveness checking plugs in as a ping function, run on every Acquire.
+package adapter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/muhlemmer/gpool"
+)
+
+// Pool adapts a gpool.Pool to Acquire, Release and Close.
+type Pool[T any] struct {
+	pool gpool.Pool[T]
+	ping func(context.Context, T) error
+}
+
+// New returns a Pool that acquires instances from p.
+// If ping is not nil, it is called on each instance before Acquire returns it.
+// An instance that fails ping is discarded through p's CloseFunc,
+// and Acquire returns the error. Pools that are not a gpool.TxnPutter
+// can't discard, so the instance is dropped instead. Callers that retry on error,
+// such as database/sql on driver.ErrBadConn, then get a next instance.
+func New[T any](p gpool.Pool[T], ping func(ctx context.Context, instance T) error) *Pool[T] {
+	return &Pool[T]{
+		pool: p,
+		ping: ping,
+	}
+}
+
+// Acquire an instance. It returns the context error if ctx is already done,
+// or the ping error when the instance failed liveness checking.
+func (a *Pool[T]) Acquire(ctx context.Context) (instance T, err error) {
+	if err = ctx.Err(); err != nil {
+		return instance, err
+	}
+
+	v := a.pool.Get()
+	if a.ping != nil {
+		if err = a.ping(ctx, v); err != nil {
+			if t, ok := a.pool.(gpool.TxnPutter[T]); ok {
+				t.BeginPut(v).Abort()
+			}
+			return instance, err
+		}
+	}
+
+	return v, nil
+}
+
+// Release an acquired instance back to the Pool.
+func (a *Pool[T]) Release(instance T) {
+	a.pool.Put(instance)
+}
+
+// Close the underlying Pool.
+func (a *Pool[T]) Close() *sync.WaitGroup {
+	return a.pool.Close()
+}
@@ -0,0 +1,92 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/muhlemmer/gpool"
+)
+
+func TestPool(t *testing.T) {
+	errDead := errors.New("dead")
+
+	closed := make(chan *int, 1)
+	a := New(gpool.NewPool(1, gpool.Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(v *int) { closed <- v },
+	}), func(_ context.Context, v *int) error {
+		if *v < 0 {
+			return errDead
+		}
+		return nil
+	})
+
+	v, err := a.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Pool.Acquire() err = %v", err)
+	}
+
+	*v = -1
+	a.Release(v)
+
+	if _, err = a.Acquire(context.Background()); err != errDead {
+		t.Errorf("Pool.Acquire() err = %v, want %v", err, errDead)
+	}
+	a.Close().Wait()
+	if w := <-closed; w != v {
+		t.Error("Pool.Acquire(): dead instance not closed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = a.Acquire(ctx); err != context.Canceled {
+		t.Errorf("Pool.Acquire() err = %v, want %v", err, context.Canceled)
+	}
+}
+
+// foreignPool implements only the gpool.Pool interface.
+type foreignPool struct {
+	c chan *int
+}
+
+func (p foreignPool) Get() *int {
+	select {
+	case v := <-p.c:
+		return v
+	default:
+		return new(int)
+	}
+}
+
+func (p foreignPool) Put(v *int) { p.c <- v }
+func (p foreignPool) Close() *sync.WaitGroup {
+	return new(sync.WaitGroup)
+}
+
+func TestPool_middlewareOverForeignPool(t *testing.T) {
+	errDead := errors.New("dead")
+
+	// Chain makes the foreign Pool look like a TxnPutter,
+	// which must not panic on the discard of a failed ping.
+	var m gpool.Metrics
+	a := New(gpool.Chain[*int](foreignPool{make(chan *int, 1)}, gpool.WithMetrics[*int](&m)),
+		func(_ context.Context, v *int) error {
+			if *v < 0 {
+				return errDead
+			}
+			return nil
+		})
+
+	v, err := a.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Pool.Acquire() err = %v", err)
+	}
+	*v = -1
+	a.Release(v)
+
+	if _, err = a.Acquire(context.Background()); err != errDead {
+		t.Errorf("Pool.Acquire() err = %v, want %v", err, errDead)
+	}
+}
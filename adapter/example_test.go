@@ -0,0 +1,64 @@
+package adapter_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/muhlemmer/gpool"
+	"github.com/muhlemmer/gpool/adapter"
+)
+
+type conn struct {
+	id     int
+	broken bool
+	closed bool
+}
+
+func ExampleNew() {
+	var dialed int
+	p := gpool.NewPool(2, gpool.Options[*conn]{
+		NewFunc: func() *conn {
+			dialed++
+			return &conn{id: dialed}
+		},
+		CloseFunc: func(c *conn) { c.closed = true },
+	})
+
+	a := adapter.New(p, func(ctx context.Context, c *conn) error {
+		if c.broken {
+			return errors.New("connection reset")
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+
+	c, err := a.Acquire(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("acquired conn", c.id)
+
+	// The peer hung up while the connection was idle in the Pool.
+	c.broken = true
+	a.Release(c)
+
+	// Ping fails, so the connection is discarded and the caller retries.
+	if _, err = a.Acquire(ctx); err != nil {
+		fmt.Println("acquire:", err)
+	}
+
+	if c, err = a.Acquire(ctx); err != nil {
+		panic(err)
+	}
+	fmt.Println("acquired conn", c.id)
+	a.Release(c)
+
+	a.Close().Wait()
+
+	// Output:
+	// acquired conn 1
+	// acquire: connection reset
+	// acquired conn 2
+}
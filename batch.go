@@ -0,0 +1,63 @@
+package gpool
+
+// BatchPool is a Pool that can hand out instances in batches,
+// reusing the backing slices of returned batches.
+type BatchPool[T any] interface {
+	Pool[T]
+
+	// GetBatch returns a slice of n instances, each obtained like Get.
+	// The slice always has length n. Its capacity may be larger
+	// when a reused slice is big enough.
+	// For n <= 0, GetBatch returns nil.
+	GetBatch(n int) []T
+
+	// PutBatch puts every instance in batch[:len(batch)], like Put,
+	// and keeps the slice for a later GetBatch.
+	// To return a partially used batch, pass only the part to be pooled.
+	// The caller must not use batch after PutBatch.
+	PutBatch(batch []T)
+}
+
+type batchPool[T any] struct {
+	Pool[T]
+	slices Pool[[]T]
+}
+
+func (p *batchPool[T]) GetOK() (T, bool) {
+	return GetOK(p.Pool)
+}
+
+func (p *batchPool[T]) GetBatch(n int) []T {
+	if n <= 0 {
+		return nil
+	}
+
+	batch := p.slices.Get()
+	if cap(batch) < n {
+		batch = make([]T, 0, n)
+	}
+	batch = batch[:n]
+
+	for i := range batch {
+		batch[i] = p.Get()
+	}
+	return batch
+}
+
+func (p *batchPool[T]) PutBatch(batch []T) {
+	var zero T
+	for i, v := range batch {
+		p.Put(v)
+		batch[i] = zero // don't keep instances alive through the slice.
+	}
+	p.slices.Put(batch[:0])
+}
+
+// NewBatchPool returns a BatchPool that gets and puts instances in p.
+// Up to "slices" amount of backing slices are kept for reuse.
+func NewBatchPool[T any](p Pool[T], slices int) BatchPool[T] {
+	return &batchPool[T]{
+		Pool:   p,
+		slices: NewPool(slices, Options[[]T]{}),
+	}
+}
@@ -0,0 +1,50 @@
+package gpool
+
+import "testing"
+
+func TestBatchPool(t *testing.T) {
+	p := NewBatchPool(NewPool(10, Options[*int]{
+		NewFunc: func() *int { return new(int) },
+	}), 1)
+
+	batch := p.GetBatch(4)
+	if len(batch) != 4 {
+		t.Fatalf("BatchPool.GetBatch(): len = %d, want %d", len(batch), 4)
+	}
+	for i, v := range batch {
+		if v == nil {
+			t.Fatalf("BatchPool.GetBatch(): entry #%d is nil", i)
+		}
+	}
+
+	first := batch[0]
+	backing := &batch[0]
+	p.PutBatch(batch)
+
+	if batch[0] != nil {
+		t.Error("BatchPool.PutBatch(): instances not cleared from slice")
+	}
+
+	batch = p.GetBatch(2)
+	if len(batch) != 2 || cap(batch) < 4 {
+		t.Errorf("BatchPool.GetBatch(): len, cap = %d, %d, want 2, >= 4", len(batch), cap(batch))
+	}
+	if &batch[0] != backing {
+		t.Error("BatchPool.GetBatch(): slice not reused")
+	}
+	if batch[0] != first {
+		t.Error("BatchPool.GetBatch(): instances not reused")
+	}
+}
+
+func TestBatchPool_GetBatchNonPositive(t *testing.T) {
+	p := NewBatchPool(NewPool(10, Options[*int]{
+		NewFunc: func() *int { return new(int) },
+	}), 1)
+
+	for _, n := range []int{0, -1} {
+		if batch := p.GetBatch(n); batch != nil {
+			t.Errorf("BatchPool.GetBatch(%d) = %v, want nil", n, batch)
+		}
+	}
+}
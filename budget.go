@@ -0,0 +1,110 @@
+package gpool
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExhausted is passed to Options.ErrorFunc when Options.BudgetFailFast is set
+// and Get could not take a permit from Options.Budget.
+var ErrBudgetExhausted = errors.New("gpool: instance budget exhausted")
+
+// Budget is a fixed amount of permits for live instances,
+// which can be shared by many Pools through Options.Budget.
+// For example, to cap the total amount of connections to a database cluster
+// across a Pool per database.
+//
+// A permit is taken for each instance from NewFunc and given back when
+// the instance is discarded, by whichever Pool sharing the Budget discards it.
+// The Budget remembers which pointer and channel instances hold a permit,
+// so discarding one that no NewFunc created, for example one passed to Put
+// from elsewhere, frees nothing. Other instances can't be told apart:
+// for those, any discard frees a permit, as long as one is taken for such an instance.
+// This bookkeeping takes a lock on each NewFunc call and discard.
+// An instance that is never discarded keeps its permit,
+// and the Budget keeps it reachable.
+type Budget struct {
+	permits chan struct{}
+
+	mu     sync.Mutex
+	live   map[any]int // Permits held per pointer-like instance.
+	values int         // Permits held by instances without identity.
+}
+
+// NewBudget returns a Budget of n permits.
+func NewBudget(n int) *Budget {
+	return &Budget{
+		permits: make(chan struct{}, n),
+		live:    make(map[any]int),
+	}
+}
+
+// InUse returns the amount of permits currently taken.
+func (b *Budget) InUse() int {
+	return len(b.permits)
+}
+
+// acquire a permit, or report false without blocking when failFast is set.
+// A nil Budget always has permits.
+func (b *Budget) acquire(failFast bool) bool {
+	if b == nil {
+		return true
+	}
+	if failFast {
+		select {
+		case b.permits <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+	b.permits <- struct{}{}
+	return true
+}
+
+// register records that v holds the permit taken for it by acquire.
+func (b *Budget) register(v any) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if hasIdentity(v) {
+		b.live[v]++
+	} else {
+		b.values++
+	}
+}
+
+// release gives back the permit of v, if v holds one.
+func (b *Budget) release(v any) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	if hasIdentity(v) {
+		n := b.live[v]
+		if n == 0 {
+			b.mu.Unlock()
+			return
+		}
+		if n == 1 {
+			delete(b.live, v)
+		} else {
+			b.live[v] = n - 1
+		}
+	} else {
+		if b.values == 0 {
+			b.mu.Unlock()
+			return
+		}
+		b.values--
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.permits:
+	default:
+	}
+}
@@ -0,0 +1,97 @@
+package gpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(2)
+	newFunc := func() *int { return new(int) }
+
+	var errs []error
+	a := NewPool(1, Options[*int]{NewFunc: newFunc, Budget: b})
+	c := NewPool(0, Options[*int]{
+		NewFunc:        newFunc,
+		Budget:         b,
+		BudgetFailFast: true,
+		ErrorFunc:      func(err error) { errs = append(errs, err) },
+	})
+
+	first, second := a.Get(), c.Get()
+	if b.InUse() != 2 {
+		t.Fatalf("Budget.InUse() = %d, want %d", b.InUse(), 2)
+	}
+
+	if v := c.Get(); v != nil {
+		t.Errorf("pool.Get() = %v, want nil on exhausted budget", v)
+	}
+	if len(errs) != 1 || errs[0] != ErrBudgetExhausted {
+		t.Errorf("ErrorFunc: errs = %v, want [%v]", errs, ErrBudgetExhausted)
+	}
+
+	got := make(chan *int)
+	go func() { got <- a.Get() }()
+
+	select {
+	case <-got:
+		t.Fatal("pool.Get(): did not block on exhausted budget")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// c has no room, so this discards second and frees its permit.
+	c.Put(second)
+	if v := <-got; v == nil {
+		t.Error("pool.Get() = nil after permit was given back")
+	}
+
+	a.Put(first)
+	if b.InUse() != 2 {
+		t.Errorf("Budget.InUse() = %d, want %d for pooled instances", b.InUse(), 2)
+	}
+}
+
+func TestBudget_foreignDiscard(t *testing.T) {
+	b := NewBudget(1)
+	newFunc := func() *int { return new(int) }
+
+	a := NewPool(0, Options[*int]{NewFunc: newFunc, Budget: b, BudgetFailFast: true})
+	c := NewPool(0, Options[*int]{NewFunc: newFunc, Budget: b, BudgetFailFast: true})
+
+	held := a.Get()
+	c.Put(new(int)) // c has no room and discards an instance it didn't create.
+
+	if _, ok := GetOK(a); ok {
+		t.Error("pool.GetOK() = true, foreign discard freed a held permit")
+	}
+	if b.InUse() != 1 {
+		t.Errorf("Budget.InUse() = %d, want %d", b.InUse(), 1)
+	}
+
+	a.Put(held)
+	if b.InUse() != 0 {
+		t.Errorf("Budget.InUse() = %d, want %d after discard by its own Pool", b.InUse(), 0)
+	}
+}
+
+func TestBudget_supervisedRestart(t *testing.T) {
+	b := NewBudget(2)
+	p := NewSupervisedPool(func() Pool[*int] {
+		return NewPool(0, Options[*int]{
+			NewFunc: func() *int { return new(int) },
+			Budget:  b,
+		})
+	})
+
+	first, second := p.Get(), p.Get()
+	p.Restart().Wait()
+
+	// The fresh Pool has no room, so it discards instances the old Pool created.
+	p.Put(first)
+	p.Put(second)
+	p.Close().Wait()
+
+	if n := b.InUse(); n != 0 {
+		t.Errorf("Budget.InUse() = %d, want %d after discarding across Restart", n, 0)
+	}
+}
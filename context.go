@@ -0,0 +1,246 @@
+package gpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrHeldPastDeadline is passed to Options.ErrorFunc when an instance from
+// ContextPool.GetWithDeadline is not returned by its deadline.
+var ErrHeldPastDeadline = errors.New("gpool: instance held past its deadline")
+
+// ContextPool is a Pool that can tie a checked out instance to a context,
+// for example the request it is used for.
+// If that context is done by the time the instance is returned,
+// the instance is discarded instead of pooled,
+// as it may still have an abandoned operation in flight,
+// such as a connection with a cancelled query.
+// It can also flag or reclaim instances that are held past a deadline.
+//
+// Put only receives the instance, so ContextPool looks up the context
+// or deadline registered at checkout with the instance itself as a map key.
+// That is why T must be comparable, and each checked out instance must be
+// a distinct value, as pointers are. Equal values, such as two identical structs,
+// would share one registration. The map is guarded by a mutex,
+// taken on each tracked Get and on each return.
+type ContextPool[T comparable] interface {
+	Pool[T]
+
+	// GetContext gets an instance from the Pool, like Get,
+	// and registers ctx for it until it is returned.
+	// When the instance is returned by Put, PutWithDeferred or BeginPut
+	// and ctx is done, it is flagged and discarded through CloseFunc.
+	// The zero value of T is not tracked.
+	GetContext(ctx context.Context) T
+
+	// GetWithHardDeadline gets an instance from the Pool, like Get,
+	// and reclaims it if it is not returned within d:
+	// a watcher calls CloseFunc on it while it is still checked out.
+	// This is only safe for types that can be closed from another Go routine,
+	// such as net.Conn, as the holder may still be using the instance.
+	// A reclaimed instance stays registered for d, or a minute if that is longer,
+	// so a return within that time is recognised and is a no-op.
+	// After that the registration is dropped, so an instance that is never returned
+	// doesn't stay reachable. The cost is that a later return
+	// pools the closed instance like any untracked one;
+	// use Options.Validators to catch closed instances on Get.
+	// The zero value of T is not tracked.
+	GetWithHardDeadline(d time.Duration) T
+
+	// GetWithDeadline gets an instance from the Pool, like Get,
+	// and returns the time by which it must be returned.
+	// If it is still checked out by then, a watcher passes ErrHeldPastDeadline to ErrorFunc,
+	// to surface slow consumers. The instance is only flagged, not reclaimed:
+	// its holder keeps using it and returns it as usual.
+	// Use GetWithHardDeadline for types that can be closed from another Go routine.
+	// The zero value of T is not tracked.
+	GetWithDeadline(d time.Duration) (instance T, deadline time.Time)
+}
+
+// minTombstone is the least amount of time a reclaimed instance stays registered.
+// It is a variable for tests.
+var minTombstone = time.Minute
+
+// checkout is a tracked instance.
+type checkout struct {
+	ctx       context.Context // Nil for deadlines.
+	timer     *time.Timer     // Nil for contexts.
+	start     time.Time       // Only set for RecordHold.
+	reclaimed bool
+}
+
+type contextPool[T comparable] struct {
+	*pool[T]
+
+	recordHold func(time.Duration)
+
+	mu        sync.Mutex
+	checkouts map[T]*checkout
+}
+
+// trackLocked registers c for v, unless v is the zero value.
+// p.mu must be held.
+func (p *contextPool[T]) trackLocked(v T, c *checkout) bool {
+	var zero T
+	if v == zero {
+		return false
+	}
+	if p.recordHold != nil {
+		c.start = time.Now()
+	}
+	p.checkouts[v] = c
+	return true
+}
+
+func (p *contextPool[T]) GetContext(ctx context.Context) T {
+	v := p.pool.Get()
+
+	p.mu.Lock()
+	p.trackLocked(v, &checkout{ctx: ctx})
+	p.mu.Unlock()
+
+	return v
+}
+
+func (p *contextPool[T]) GetWithHardDeadline(d time.Duration) T {
+	v := p.pool.Get()
+
+	// Hold the lock until the timer is set,
+	// so reclaim always finds the checkout registered.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c := new(checkout)
+	if p.trackLocked(v, c) {
+		c.timer = time.AfterFunc(d, func() { p.reclaim(v, c, d) })
+	}
+	return v
+}
+
+func (p *contextPool[T]) GetWithDeadline(d time.Duration) (T, time.Time) {
+	v := p.pool.Get()
+	deadline := time.Now().Add(d)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c := new(checkout)
+	if p.trackLocked(v, c) {
+		c.timer = time.AfterFunc(d, func() { p.flag(v, c) })
+	}
+	return v, deadline
+}
+
+// flag reports v as held past its deadline if c is still its checkout.
+func (p *contextPool[T]) flag(v T, c *checkout) {
+	p.mu.Lock()
+	held := p.checkouts[v] == c
+	p.mu.Unlock()
+
+	if held {
+		p.maybeError(ErrHeldPastDeadline)
+	}
+}
+
+// reclaim closes v if c is still its checkout,
+// and keeps c registered for the tombstone time derived from d.
+func (p *contextPool[T]) reclaim(v T, c *checkout, d time.Duration) {
+	if d < minTombstone {
+		d = minTombstone
+	}
+
+	p.mu.Lock()
+	if p.checkouts[v] != c {
+		p.mu.Unlock()
+		return
+	}
+	c.reclaimed = true
+	c.timer = time.AfterFunc(d, func() { p.expire(v, c) })
+	p.mu.Unlock()
+
+	p.maybeClose(v)
+}
+
+// expire drops the registration of reclaimed v, if c is still its checkout.
+func (p *contextPool[T]) expire(v T, c *checkout) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.checkouts[v] == c {
+		delete(p.checkouts, v)
+	}
+}
+
+type returnAction int
+
+const (
+	returnPool returnAction = iota
+	returnDiscard
+	returnDrop
+)
+
+// untrack unregisters v and tells what to do with it.
+// reclaim no longer touches c once it is removed from the map.
+func (p *contextPool[T]) untrack(v T) returnAction {
+	p.mu.Lock()
+	c, ok := p.checkouts[v]
+	delete(p.checkouts, v)
+	p.mu.Unlock()
+
+	if !ok {
+		return returnPool
+	}
+	if p.recordHold != nil {
+		p.recordHold(time.Since(c.start))
+	}
+
+	switch {
+	case c.reclaimed:
+		c.timer.Stop()
+		return returnDrop
+	case c.timer != nil:
+		c.timer.Stop()
+	case c.ctx.Err() != nil:
+		return returnDiscard
+	}
+	return returnPool
+}
+
+func (p *contextPool[T]) Put(v T) {
+	switch p.untrack(v) {
+	case returnDiscard:
+		p.maybeClose(v)
+	case returnPool:
+		p.pool.Put(v)
+	}
+}
+
+func (p *contextPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	switch p.untrack(v) {
+	case returnDiscard:
+		p.maybeClose(v)
+	case returnPool:
+		p.pool.PutWithDeferred(v, onNextGet)
+	}
+}
+
+func (p *contextPool[T]) BeginPut(v T) PutTxn {
+	put, discard := p.pool.Put, p.maybeClose
+	switch p.untrack(v) {
+	case returnDiscard:
+		put = p.maybeClose
+	case returnDrop:
+		put, discard = func(T) {}, func(T) {}
+	}
+	return newPutTxn(v, put, discard, p.leakFunc())
+}
+
+// NewContextPool returns a ContextPool that can hold "size" amount of instances of T.
+func NewContextPool[T comparable](size int, opt Options[T]) ContextPool[T] {
+	return &contextPool[T]{
+		pool:       newPool(size, opt),
+		recordHold: opt.RecordHold,
+		checkouts:  make(map[T]*checkout),
+	}
+}
@@ -0,0 +1,169 @@
+package gpool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestContextPool(t *testing.T) {
+	closed := make(chan *int, 1)
+	p := NewContextPool(1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(v *int) { closed <- v },
+	})
+
+	t.Run("context alive", func(t *testing.T) {
+		v := p.GetContext(context.Background())
+		p.Put(v)
+
+		if w := p.Get(); w != v {
+			t.Error("ContextPool.Put(): instance with live context not pooled")
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		v := p.GetContext(ctx)
+		cancel()
+		p.Put(v)
+
+		if w := p.Get(); w == v {
+			t.Error("ContextPool.Put(): flagged instance pooled")
+		}
+		p.Close().Wait()
+		if w := <-closed; w != v {
+			t.Error("ContextPool.Put(): flagged instance not closed")
+		}
+	})
+}
+
+func TestContextPool_GetWithHardDeadline(t *testing.T) {
+	closed := make(chan *int, 1)
+	p := NewContextPool(1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(v *int) { closed <- v },
+	})
+
+	t.Run("returned in time", func(t *testing.T) {
+		v := p.GetWithHardDeadline(time.Hour)
+		p.Put(v)
+
+		if w := p.Get(); w != v {
+			t.Error("ContextPool.Put(): instance returned in time not pooled")
+		}
+	})
+
+	t.Run("reclaimed", func(t *testing.T) {
+		v := p.GetWithHardDeadline(time.Millisecond)
+
+		select {
+		case w := <-closed:
+			if w != v {
+				t.Error("ContextPool.GetWithHardDeadline(): closed wrong instance")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("ContextPool.GetWithHardDeadline(): instance not reclaimed")
+		}
+
+		p.Put(v)
+		if w := p.Get(); w == v {
+			t.Error("ContextPool.Put(): reclaimed instance pooled")
+		}
+		select {
+		case <-closed:
+			t.Error("ContextPool.Put(): reclaimed instance closed twice")
+		default:
+		}
+	})
+}
+
+func TestContextPool_GetWithHardDeadline_tombstone(t *testing.T) {
+	defer func(d time.Duration) { minTombstone = d }(minTombstone)
+	minTombstone = 10 * time.Millisecond
+
+	closed := make(chan *int, 1)
+	p := NewContextPool(1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(v *int) { closed <- v },
+	})
+	cp := p.(*contextPool[*int])
+
+	v := p.GetWithHardDeadline(time.Millisecond)
+	<-closed
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		cp.mu.Lock()
+		n := len(cp.checkouts)
+		cp.mu.Unlock()
+
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ContextPool: %d registrations kept after the tombstone expired", n)
+		}
+	}
+
+	// The registration is gone, so the late return is pooled.
+	p.Put(v)
+	if w := p.Get(); w != v {
+		t.Error("ContextPool.Put(): return after the tombstone expired not pooled")
+	}
+}
+
+func TestContextPool_GetWithDeadline(t *testing.T) {
+	errs := make(chan error, 1)
+	p := NewContextPool(1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		ErrorFunc: func(err error) { errs <- err },
+	})
+
+	t.Run("returned in time", func(t *testing.T) {
+		v, deadline := p.GetWithDeadline(time.Hour)
+		if until := time.Until(deadline); until <= 0 || until > time.Hour {
+			t.Errorf("ContextPool.GetWithDeadline(): deadline in %v, want within %v", until, time.Hour)
+		}
+		p.Put(v)
+
+		if w := p.Get(); w != v {
+			t.Error("ContextPool.Put(): instance returned in time not pooled")
+		}
+	})
+
+	t.Run("held past deadline", func(t *testing.T) {
+		v, _ := p.GetWithDeadline(time.Millisecond)
+
+		select {
+		case err := <-errs:
+			if err != ErrHeldPastDeadline {
+				t.Errorf("ErrorFunc: err = %v, want %v", err, ErrHeldPastDeadline)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("ContextPool.GetWithDeadline(): instance not flagged")
+		}
+
+		p.Put(v)
+		if w := p.Get(); w != v {
+			t.Error("ContextPool.Put(): flagged instance not pooled")
+		}
+	})
+}
+
+func TestContextPool_RecordHold(t *testing.T) {
+	var held []time.Duration
+	p := NewContextPool(1, Options[*int]{
+		NewFunc:    func() *int { return new(int) },
+		RecordHold: func(d time.Duration) { held = append(held, d) },
+	})
+
+	v := p.GetContext(context.Background())
+	time.Sleep(time.Millisecond)
+	p.Put(v)
+
+	p.Put(p.Get()) // untracked, not recorded.
+
+	if len(held) != 1 || held[0] < time.Millisecond {
+		t.Errorf("RecordHold: held = %v, want one hold of at least %v", held, time.Millisecond)
+	}
+}
@@ -0,0 +1,29 @@
+package gpool
+
+import (
+	"bytes"
+	"strings"
+)
+
+// NewBufferPool is a convenience constructor for a Pool of bytes.Buffer.
+// Buffers are created empty and Reset on Put, so they keep their capacity.
+func NewBufferPool(size int) Pool[*bytes.Buffer] {
+	return NewResetterPoolPtr[bytes.Buffer](size, Options[*bytes.Buffer]{})
+}
+
+// NewBuilderPool is a convenience constructor for a Pool of strings.Builder.
+// Builders are created empty and Reset on Put.
+// Note that strings.Builder drops its buffer on Reset,
+// so only the Builder itself is reused.
+func NewBuilderPool(size int) Pool[*strings.Builder] {
+	return NewResetterPoolPtr[strings.Builder](size, Options[*strings.Builder]{})
+}
+
+// NewByteReaderPool is a convenience constructor for a Pool of bytes.Reader.
+// Readers are created empty and Reset to read nothing on Put,
+// so they don't keep the previous slice alive.
+func NewByteReaderPool(size int) Pool[*bytes.Reader] {
+	return newResetPool(size, Options[*bytes.Reader]{
+		NewFunc: func() *bytes.Reader { return bytes.NewReader(nil) },
+	}, func(r *bytes.Reader) { r.Reset(nil) })
+}
@@ -0,0 +1,50 @@
+package gpool
+
+import (
+	"io"
+	"testing"
+)
+
+func TestNewBufferPool(t *testing.T) {
+	p := NewBufferPool(1)
+
+	b := p.Get()
+	b.WriteString("hello")
+	p.Put(b)
+
+	b = p.Get()
+	if c := b.Cap(); c == 0 {
+		t.Errorf("NewBufferPool: cap = %d", c)
+	}
+	if l := b.Len(); l != 0 {
+		t.Errorf("NewBufferPool: len = %d, want %d", l, 0)
+	}
+}
+
+func TestNewBuilderPool(t *testing.T) {
+	p := NewBuilderPool(1)
+
+	b := p.Get()
+	b.WriteString("hello")
+	p.Put(b)
+
+	if b = p.Get(); b.Len() != 0 {
+		t.Errorf("NewBuilderPool: len = %d, want %d", b.Len(), 0)
+	}
+}
+
+func TestNewByteReaderPool(t *testing.T) {
+	p := NewByteReaderPool(1)
+
+	r := p.Get()
+	r.Reset([]byte("hello"))
+	p.Put(r)
+
+	r = p.Get()
+	if n := r.Size(); n != 0 {
+		t.Errorf("NewByteReaderPool: size = %d, want %d", n, 0)
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Errorf("NewByteReaderPool: ReadByte() err = %v, want %v", err, io.EOF)
+	}
+}
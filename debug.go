@@ -0,0 +1,96 @@
+package gpool
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ErrDuplicateNew is passed to Options.ErrorFunc when Options.DetectDuplicateNew is set
+// and NewFunc returned an instance it returned before.
+var ErrDuplicateNew = errors.New("gpool: NewFunc returned an instance already in circulation")
+
+// dupWindow is the amount of created instances a dupDetector remembers.
+const dupWindow = 16
+
+// dupDetector remembers the last dupWindow created instances.
+type dupDetector struct {
+	mu   sync.Mutex
+	seen [dupWindow]any
+	next int
+}
+
+// hasIdentity reports if v compares by identity with ==:
+// pointers, channels and unsafe pointers.
+// Value types, and structs or arrays that may hold interfaces of uncomparable types,
+// are excluded; comparing those would be by value or could panic.
+func hasIdentity(v any) bool {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *dupDetector) check(v any, errFunc func(error)) {
+	if !hasIdentity(v) {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, s := range d.seen {
+		if s != nil && s == v {
+			errFunc(ErrDuplicateNew)
+			return
+		}
+	}
+
+	d.seen[d.next] = v
+	d.next = (d.next + 1) % dupWindow
+}
+
+// DiscardTrace is passed to Options.ErrorFunc when Options.TraceDiscards is set
+// and Put discarded an instance because the Pool was full.
+type DiscardTrace struct {
+	// Stack holds the program counters of the discarding Put call,
+	// as returned by runtime.Callers.
+	Stack []uintptr
+}
+
+// traceDepth is the maximum amount of frames captured by a DiscardTrace.
+const traceDepth = 32
+
+// discardTraceSkip skips runtime.Callers, newDiscardTrace, discardFull, overflow and put,
+// so a DiscardTrace starts at Put.
+const discardTraceSkip = 5
+
+func newDiscardTrace() *DiscardTrace {
+	pc := make([]uintptr, traceDepth)
+	n := runtime.Callers(discardTraceSkip, pc)
+	return &DiscardTrace{pc[:n]}
+}
+
+func (t *DiscardTrace) Error() string {
+	var b strings.Builder
+	b.WriteString("gpool: Put discarded instance on full Pool at:")
+
+	frames := runtime.CallersFrames(t.Stack)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "\n\t%s\n\t\t%s:%d", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
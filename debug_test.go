@@ -0,0 +1,108 @@
+package gpool
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPool_DetectDuplicateNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		newFunc func() *int
+		want    int
+	}{
+		{
+			"unique",
+			func() *int { return new(int) },
+			0,
+		},
+		{
+			"singleton",
+			func() func() *int {
+				v := new(int)
+				return func() *int { return v }
+			}(),
+			2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			p := NewPool(0, Options[*int]{
+				NewFunc:            tt.newFunc,
+				DetectDuplicateNew: true,
+				ErrorFunc:          func(err error) { errs = append(errs, err) },
+			})
+
+			for i := 0; i < 3; i++ {
+				p.Get()
+			}
+
+			if len(errs) != tt.want {
+				t.Fatalf("ErrorFunc called %d times, want %d", len(errs), tt.want)
+			}
+			for _, err := range errs {
+				if err != ErrDuplicateNew {
+					t.Errorf("ErrorFunc: err = %v, want %v", err, ErrDuplicateNew)
+				}
+			}
+		})
+	}
+}
+
+func TestPool_DetectDuplicateNew_values(t *testing.T) {
+	type holder struct{ v any }
+
+	var errs []error
+	errFunc := func(err error) { errs = append(errs, err) }
+
+	ints := NewPool(0, Options[int]{
+		NewFunc:            func() int { return 7 },
+		DetectDuplicateNew: true,
+		ErrorFunc:          errFunc,
+	})
+	// holder is comparable, but == panics on the slice it holds.
+	holders := NewPool(0, Options[holder]{
+		NewFunc:            func() holder { return holder{[]int{1}} },
+		DetectDuplicateNew: true,
+		ErrorFunc:          errFunc,
+	})
+
+	for i := 0; i < 3; i++ {
+		ints.Get()
+		holders.Get()
+	}
+
+	if len(errs) != 0 {
+		t.Errorf("ErrorFunc called with %v, want no errors for value types", errs)
+	}
+}
+
+func TestPool_TraceDiscards(t *testing.T) {
+	var errs []error
+	p := NewPool(0, Options[int]{
+		TraceDiscards: true,
+		ErrorFunc:     func(err error) { errs = append(errs, err) },
+	})
+
+	p.Put(1)
+
+	if len(errs) != 1 {
+		t.Fatalf("ErrorFunc called %d times, want 1", len(errs))
+	}
+	var trace *DiscardTrace
+	if !errors.As(errs[0], &trace) {
+		t.Fatalf("ErrorFunc: err = %T, want %T", errs[0], trace)
+	}
+	if msg := trace.Error(); !strings.Contains(msg, "TestPool_TraceDiscards") {
+		t.Errorf("DiscardTrace.Error() = %q, want caller in stack", msg)
+	}
+
+	first, _ := runtime.CallersFrames(trace.Stack).Next()
+	if !strings.HasSuffix(first.Function, ").Put") {
+		t.Errorf("DiscardTrace.Stack starts at %s, want pool.Put", first.Function)
+	}
+}
@@ -0,0 +1,79 @@
+package gpool
+
+import "fmt"
+
+// EventKind identifies what happened in a Pool for an Event.
+type EventKind int
+
+const (
+	// EventGet is sent when Get or GetOK hands out an instance,
+	// either from the buffer or from NewFunc.
+	EventGet EventKind = iota + 1
+
+	// EventPut is sent when an instance is stored in the buffer.
+	// A Put that overflows sends EventDiscard instead.
+	EventPut
+
+	// EventCreate is sent when NewFunc returned an instance that passed Acquire.
+	EventCreate
+
+	// EventDiscard is sent when an instance is discarded to CloseFunc,
+	// or would be if the Pool has none.
+	EventDiscard
+
+	// EventClose is sent when Close is called, before the buffered instances are discarded.
+	EventClose
+
+	// EventValidationFail is sent when an instance fails a validator or Acquire.
+	// Err holds the error of the validator.
+	// The instance is discarded next, which sends EventDiscard.
+	EventValidationFail
+
+	// EventError is sent for each error passed to ErrorFunc,
+	// also if ErrorFunc is nil. Err holds the error.
+	EventError
+
+	// EventDrained is sent each time OnDrained would be called, also if OnDrained is nil.
+	EventDrained
+)
+
+var eventKindNames = [...]string{
+	EventGet:            "Get",
+	EventPut:            "Put",
+	EventCreate:         "Create",
+	EventDiscard:        "Discard",
+	EventClose:          "Close",
+	EventValidationFail: "ValidationFail",
+	EventError:          "Error",
+	EventDrained:        "Drained",
+}
+
+func (k EventKind) String() string {
+	if k > 0 && int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is sent on Options.Events. Kind tells which of the other fields are set.
+type Event[T any] struct {
+	Kind EventKind
+
+	// Instance is set for EventGet, EventPut, EventCreate,
+	// EventDiscard and EventValidationFail.
+	Instance T
+
+	// Err is set for EventValidationFail and EventError.
+	Err error
+}
+
+// emit sends an Event if Events is set, dropping it when the consumer is not ready.
+func (p *pool[T]) emit(kind EventKind, v T, err error) {
+	if p.events == nil {
+		return
+	}
+	select {
+	case p.events <- Event[T]{Kind: kind, Instance: v, Err: err}:
+	default:
+	}
+}
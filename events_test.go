@@ -0,0 +1,86 @@
+package gpool
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPool_Events(t *testing.T) {
+	errNegative := errors.New("negative")
+	events := make(chan Event[int], 32)
+
+	var n int
+	p := NewPool(1, Options[int]{
+		NewFunc: func() int { n++; return n },
+		Validators: []func(int) error{
+			func(v int) error {
+				if v < 0 {
+					return errNegative
+				}
+				return nil
+			},
+		},
+		Events: events,
+	})
+
+	p.Get()
+	p.Put(1)
+	p.Put(2)
+	p.Get()
+	p.Put(-1)
+	p.Get()
+	p.Close().Wait()
+	close(events)
+
+	var got []Event[int]
+	for e := range events {
+		got = append(got, e)
+	}
+
+	var valErr *ValidationError
+	if len(got) != 14 || !errors.Is(got[8].Err, errNegative) || !errors.As(got[9].Err, &valErr) {
+		t.Fatalf("events = %v", got)
+	}
+	got[8].Err, got[9].Err = nil, nil
+
+	want := []Event[int]{
+		{Kind: EventCreate, Instance: 1},
+		{Kind: EventGet, Instance: 1},
+		{Kind: EventPut, Instance: 1},
+		{Kind: EventDiscard, Instance: 2},
+		{Kind: EventDrained},
+		{Kind: EventGet, Instance: 1},
+		{Kind: EventPut, Instance: -1},
+		{Kind: EventDrained},
+		{Kind: EventValidationFail, Instance: -1},
+		{Kind: EventError},
+		{Kind: EventDiscard, Instance: -1},
+		{Kind: EventCreate, Instance: 2},
+		{Kind: EventGet, Instance: 2},
+		{Kind: EventClose},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestPool_Events_dropped(t *testing.T) {
+	p := NewPool(1, Options[int]{
+		Events: make(chan Event[int]),
+	})
+
+	// Without a receiver, each send must be dropped instead of blocking.
+	p.Put(1)
+	p.Get()
+	p.Close().Wait()
+}
+
+func TestEventKind_String(t *testing.T) {
+	if got := EventValidationFail.String(); got != "ValidationFail" {
+		t.Errorf("EventValidationFail.String() = %q", got)
+	}
+	if got := EventKind(0).String(); got != "EventKind(0)" {
+		t.Errorf("EventKind(0).String() = %q", got)
+	}
+}
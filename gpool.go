@@ -1,6 +1,9 @@
 // Package gpool provides a generic alternative to sync.Pool.
 //
-// It is implemented using channels and does not use any other form of locking.
+// Get and Put are implemented using channels and atomic operations,
+// without any other form of locking. Debugging aids such as DetectDuplicateNew,
+// and the Pool types that track checked out instances or keep a heap,
+// such as ContextPool and PriorityPool, use a mutex as their documentation states.
 // The main difference with sync.Pool, is that instances in the Pool
 // don't get garbage collected every other run and the size of the Pool is fixed.
 // Also by using type parameters, this package is generic and can be used without
@@ -9,10 +12,54 @@
 package gpool
 
 import (
+	"errors"
+	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrCloseTimeout is passed to Options.ErrorFunc when a CloseFunc
+// call did not return within Options.CloseTimeout.
+var ErrCloseTimeout = errors.New("gpool: CloseFunc timed out")
+
+// ErrZeroReturn is passed to Options.ErrorFunc when Options.WarnOnZeroReturn is set
+// and Get returned the zero value of T, because the Pool was empty and NewFunc is nil.
+var ErrZeroReturn = errors.New("gpool: Get on empty Pool without NewFunc returned zero value")
+
+// ErrUnhealthy is the error in a ValidationError for a Healther that reported it is not healthy.
+var ErrUnhealthy = errors.New("gpool: instance not healthy")
+
+// ValidationError is passed to Options.ErrorFunc when Get discards
+// an instance because it failed validation.
+type ValidationError struct {
+	// Err is the reason the instance failed.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("gpool: instance discarded: %v", e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
 // Pool allows reuse of memory between Go routines.
+//
+// The Pools of this package also implement the optional interfaces
+// OKGetter, DeferredPutter, TxnPutter, Recloser and GroupCloser.
+// Check for them with a type assertion, for example:
+//
+//	if d, ok := p.(gpool.DeferredPutter[*Conn]); ok {
+//		d.PutWithDeferred(conn, reauth)
+//	}
+//
+// The functions GetOK, PutWithDeferred, BeginPut, RecloseBuffered and CloseWithGroup do this check,
+// and fall back to a safe default when the Pool does not implement the interface.
+// The Middleware of this package, SupervisedPool and gpooltest.LeakChecker
+// forward through them to the Pool they wrap.
 type Pool[T any] interface {
 	// Get an instance from the Pool,
 	// or NewFunc if it's not nil.
@@ -28,58 +75,561 @@ type Pool[T any] interface {
 	// If the Pool was created with a CloseFunc,
 	// it is called for each instance in a seperate Go routine.
 	// Callers can Wait() on all routines to finish.
+	// Get remains safe to call during and after Close,
+	// and only returns instances from NewFunc.
+	// The Pools of this package discard instances Put during or after Close.
+	// Other implementations may not allow a Put after Close.
 	Close() *sync.WaitGroup
 }
 
+// OKGetter is a Pool that supports GetOK.
+// See Pool for how to check for it.
+type OKGetter[T any] interface {
+	// GetOK is like Get, and reports if an instance was available.
+	// It returns false only when it returns the zero value because
+	// the Pool was empty and NewFunc is nil or could not provide an instance,
+	// for example on an exhausted Budget with BudgetFailFast.
+	// A reused or new instance always reports true, even if it equals the zero value,
+	// so value types can tell the difference.
+	GetOK() (instance T, ok bool)
+}
+
+// DeferredPutter is a Pool that supports PutWithDeferred.
+// See Pool for how to check for it.
+type DeferredPutter[T any] interface {
+	// PutWithDeferred puts an instance in the pool, like Put,
+	// together with a callback for its next user.
+	// onNextGet is run exactly once, by the next Get that hands out this instance,
+	// before Get returns.
+	// If the instance is discarded instead, onNextGet is never run.
+	PutWithDeferred(instance T, onNextGet func(T))
+}
+
+// Recloser is a Pool that supports RecloseBuffered.
+// See Pool for how to check for it.
+type Recloser[T any] interface {
+	// RecloseBuffered replaces the CloseFunc for all future discards by closeFunc,
+	// for example after fixing a CloseFunc that leaked resources.
+	// It then discards the instances buffered at the time of the call with closeFunc,
+	// so the Pool is drained in the process and refills from NewFunc and Put.
+	// closeFunc may be nil. Callers can Wait() on the returned WaitGroup,
+	// which is the same as the one returned by Close.
+	RecloseBuffered(closeFunc func(instance T)) *sync.WaitGroup
+}
+
+// GetOK calls p.GetOK if p is an OKGetter.
+// Otherwise it returns p.Get and reports true, as p can't tell.
+func GetOK[T any](p Pool[T]) (T, bool) {
+	if g, ok := p.(OKGetter[T]); ok {
+		return g.GetOK()
+	}
+	return p.Get(), true
+}
+
+// PutWithDeferred calls p.PutWithDeferred if p is a DeferredPutter.
+// Otherwise the instance is discarded like a TxnPutter Abort, or dropped,
+// as a plain Put would hand it out without running onNextGet.
+func PutWithDeferred[T any](p Pool[T], v T, onNextGet func(T)) {
+	if d, ok := p.(DeferredPutter[T]); ok {
+		d.PutWithDeferred(v, onNextGet)
+		return
+	}
+	discard(p, v)
+}
+
+// RecloseBuffered calls p.RecloseBuffered if p is a Recloser.
+// Otherwise it does nothing, keeping the CloseFunc and buffered instances of p,
+// and returns a WaitGroup with nothing to wait for.
+func RecloseBuffered[T any](p Pool[T], closeFunc func(T)) *sync.WaitGroup {
+	if r, ok := p.(Recloser[T]); ok {
+		return r.RecloseBuffered(closeFunc)
+	}
+	return new(sync.WaitGroup)
+}
+
+// entry is a buffered instance with an optional
+// callback to run before it is handed out again.
+type entry[T any] struct {
+	v         T
+	onNextGet func(T)
+}
+
 type pool[T any] struct {
-	c     chan T
-	new   func() T
-	close func(T)
-	wg    sync.WaitGroup
+	// nextClose is the UnixNano of the next slot allowed by closeEvery.
+	// First field, for 64-bit alignment of atomic access on 32-bit platforms.
+	nextClose int64
+
+	c              chan entry[T]
+	new            func() T
+	close          atomic.Value // func(T), which RecloseBuffered may replace.
+	closeTimeout   time.Duration
+	closeEvery     time.Duration
+	errFunc        func(error)
+	warnZero       bool
+	dups           *dupDetector
+	creating       chan struct{} // Token for CoalesceCreates.
+	budget         *Budget
+	budgetFailFast bool
+	onFull         func(T) FullAction
+	putGrace       time.Duration
+	traceDiscards  bool
+	validators     []func(T) error
+	maxValidate    int
+	acquire        func(T) error // Acquirer.Acquire, after validators and on new instances.
+	events         chan<- Event[T]
+	onDrained      func()
+	drained        int32         // 1 once onDrained fired, until re-armed by put.
+	closed         int32         // 1 once Close started, so put discards.
+	putters        int32         // Amount of put calls in progress.
+	stopValidate   chan struct{} // Closed by Close to stop validateBuffered.
+	validateDone   chan struct{}
+	wg             sync.WaitGroup
+}
+
+func (p *pool[T]) maybeNew() T {
+	v, _ := p.tryNew()
+	return v
 }
 
-func (p *pool[T]) maybeNew() (v T) {
-	if p.new != nil {
-		return p.new()
+// tryNew calls NewFunc, and reports false if no instance could be created.
+func (p *pool[T]) tryNew() (v T, ok bool) {
+	if p.new == nil {
+		if p.warnZero {
+			p.maybeError(ErrZeroReturn)
+		}
+		return v, false
+	}
+	if !p.budget.acquire(p.budgetFailFast) {
+		p.maybeError(ErrBudgetExhausted)
+		return v, false
+	}
+
+	w := p.new()
+	p.budget.register(w)
+	if p.dups != nil {
+		p.dups.check(w, p.maybeError)
 	}
-	return
+	if p.acquire != nil {
+		if err := p.acquire(w); err != nil {
+			p.discardInvalid(w, err)
+			return v, false
+		}
+	}
+	p.emit(EventCreate, w, nil)
+	return w, true
 }
 
 func (p *pool[T]) maybeClose(v T) {
-	if p.close != nil {
-		p.wg.Add(1)
+	closeFunc := p.discarding(v)
+	if closeFunc == nil {
+		return
+	}
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+		p.runClose(closeFunc, v)
+	}()
+}
+
+// discarding starts the discard of v and returns the CloseFunc to run.
+// If there is none, v is done with and discarding returns nil.
+func (p *pool[T]) discarding(v T) func(T) {
+	closeFunc, _ := p.close.Load().(func(T))
+
+	p.emit(EventDiscard, v, nil)
 
-		go func() {
-			defer p.wg.Done()
-			p.close(v)
-		}()
+	if closeFunc == nil {
+		p.budget.release(v)
 	}
+	return closeFunc
 }
 
-func (p *pool[T]) Get() T {
+// runClose runs closeFunc on v for a discard started by discarding,
+// honoring CloseRate and CloseTimeout.
+func (p *pool[T]) runClose(closeFunc func(T), v T) error {
+	defer p.budget.release(v)
+	p.waitCloseSlot()
+	return p.callClose(closeFunc, v)
+}
+
+// waitCloseSlot blocks until CloseRate allows the next CloseFunc call.
+// Each caller claims the next free slot, so calls are spaced evenly without bursts.
+func (p *pool[T]) waitCloseSlot() {
+	if p.closeEvery <= 0 {
+		return
+	}
+
+	for {
+		now := time.Now().UnixNano()
+		next := atomic.LoadInt64(&p.nextClose)
+
+		slot := next
+		if slot < now {
+			slot = now
+		}
+
+		if atomic.CompareAndSwapInt64(&p.nextClose, next, slot+int64(p.closeEvery)) {
+			time.Sleep(time.Duration(slot - now))
+			return
+		}
+	}
+}
+
+func (p *pool[T]) maybeError(err error) {
+	var zero T
+	p.emit(EventError, zero, err)
+	if p.errFunc != nil {
+		p.errFunc(err)
+	}
+}
+
+// callClose returns ErrCloseTimeout if closeFunc did not return within CloseTimeout.
+func (p *pool[T]) callClose(closeFunc func(T), v T) error {
+	if p.closeTimeout <= 0 {
+		closeFunc(v)
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		closeFunc(v)
+	}()
+
+	timer := time.NewTimer(p.closeTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		p.maybeError(ErrCloseTimeout)
+		return ErrCloseTimeout
+	}
+}
+
+// tryGet takes an entry from the buffer.
+// It returns false if the buffer is empty or closed.
+func (p *pool[T]) tryGet() (e entry[T], ok bool) {
 	select {
-	case v := <-p.c:
-		return v
+	case e, ok = <-p.c:
+		// ok is false when closed concurrently with this Get.
+		if ok {
+			p.maybeDrained()
+		}
+		return e, ok
 	default:
-		return p.maybeNew()
+		return e, false
+	}
+}
+
+func (p *pool[T]) Get() T {
+	v, _ := p.GetOK()
+	return v
+}
+
+func (p *pool[T]) GetOK() (T, bool) {
+	v, ok := p.get()
+	if ok {
+		p.emit(EventGet, v, nil)
 	}
+	return v, ok
+}
+
+func (p *pool[T]) get() (T, bool) {
+	for discarded := 0; ; {
+		if p.maxValidate > 0 && discarded >= p.maxValidate {
+			return p.tryNew()
+		}
+
+		e, ok := p.tryGet()
+		if !ok {
+			if p.creating == nil {
+				return p.tryNew()
+			}
+			var buffered bool
+			if e, buffered, ok = p.awaitCreate(); !buffered {
+				return e.v, ok
+			}
+		}
+		err := p.validate(e.v)
+		if err == nil && p.acquire != nil {
+			err = p.acquire(e.v)
+		}
+		if err != nil {
+			p.discardInvalid(e.v, err)
+			discarded++
+			continue
+		}
+		if e.onNextGet != nil {
+			e.onNextGet(e.v)
+		}
+		return e.v, true
+	}
+}
+
+// validate runs the validators in order, returning the first error.
+func (p *pool[T]) validate(v T) error {
+	for _, f := range p.validators {
+		if err := f(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discardInvalid discards v, which failed validation with err.
+func (p *pool[T]) discardInvalid(v T, err error) {
+	p.emit(EventValidationFail, v, err)
+	p.maybeError(&ValidationError{err})
+	p.maybeClose(v)
+}
+
+// validateBuffered checks the oldest buffered instance with the validators
+// every interval, until Close. An instance that passes goes back to the end of the buffer,
+// so the buffered instances are checked in turn.
+func (p *pool[T]) validateBuffered(interval time.Duration) {
+	defer close(p.validateDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopValidate:
+			return
+		case <-ticker.C:
+		}
+
+		select {
+		case e := <-p.c:
+			if err := p.validate(e.v); err != nil {
+				p.discardInvalid(e.v, err)
+				continue
+			}
+			select {
+			case p.c <- e:
+			default:
+				// Refilled by Puts in the meantime.
+				p.maybeClose(e.v)
+			}
+		default:
+		}
+	}
+}
+
+// awaitCreate waits for either a Put or its turn to call NewFunc.
+// It returns a Put entry with buffered true, or the result of tryNew.
+func (p *pool[T]) awaitCreate() (e entry[T], buffered, ok bool) {
+	select {
+	case p.creating <- struct{}{}:
+		defer func() { <-p.creating }()
+		e.v, ok = p.tryNew()
+		return e, false, ok
+	case e, buffered = <-p.c:
+		if !buffered {
+			// Closed concurrently with this Get.
+			e.v, ok = p.tryNew()
+			return e, false, ok
+		}
+		p.maybeDrained()
+		return e, true, true
+	}
+}
+
+func (p *pool[T]) maybeDrained() {
+	if (p.onDrained != nil || p.events != nil) && len(p.c) == 0 && atomic.CompareAndSwapInt32(&p.drained, 0, 1) {
+		var zero T
+		p.emit(EventDrained, zero, nil)
+		if p.onDrained != nil {
+			p.onDrained()
+		}
+	}
+}
+
+// maybeRearm re-arms onDrained once the buffer is refilled to at least half.
+func (p *pool[T]) maybeRearm() {
+	if (p.onDrained != nil || p.events != nil) && atomic.LoadInt32(&p.drained) == 1 && len(p.c) >= (cap(p.c)+1)/2 {
+		atomic.StoreInt32(&p.drained, 0)
+	}
+}
+
+// buffered is called after v was stored in the buffer.
+func (p *pool[T]) buffered(v T) {
+	p.emit(EventPut, v, nil)
+	p.maybeRearm()
 }
 
 func (p *pool[T]) Put(v T) {
+	p.put(entry[T]{v: v})
+}
+
+func (p *pool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	p.put(entry[T]{v: v, onNextGet: onNextGet})
+}
+
+func (p *pool[T]) BeginPut(v T) PutTxn {
+	return newPutTxn(v, p.Put, p.maybeClose, p.leakFunc())
+}
+
+// leakFunc returns the errFunc for newPutTxn: nil when neither ErrorFunc nor Events
+// would receive ErrPutTxnLeaked, so no finalizer is set.
+func (p *pool[T]) leakFunc() func(error) {
+	if p.errFunc == nil && p.events == nil {
+		return nil
+	}
+	return p.maybeError
+}
+
+func (p *pool[T]) put(e entry[T]) {
+	// Close waits for putters to leave before closing the channel,
+	// and a putter that arrives after Close sees closed.
+	atomic.AddInt32(&p.putters, 1)
+	defer atomic.AddInt32(&p.putters, -1)
+	if atomic.LoadInt32(&p.closed) == 1 {
+		p.maybeClose(e.v)
+		return
+	}
+
 	select {
-	case p.c <- v:
+	case p.c <- e:
+		p.buffered(e.v)
 	default:
-		p.maybeClose(v)
+		if p.putGrace > 0 && p.sendWithin(e, p.putGrace) {
+			return
+		}
+		p.overflow(e)
+	}
+}
+
+// sendWithin tries to put e in the buffer until d has passed.
+func (p *pool[T]) sendWithin(e entry[T], d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case p.c <- e:
+		p.buffered(e.v)
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// FullAction is what Put does with an instance when the Pool is full.
+type FullAction int
+
+const (
+	// FullDiscard discards the instance, calling CloseFunc. This is the default.
+	FullDiscard FullAction = iota
+
+	// FullEvict discards the oldest buffered instance to make room.
+	// If it can't, because the Pool has size 0 or concurrent Puts keep taking the room,
+	// the instance is discarded as with FullDiscard.
+	FullEvict
+
+	// FullBlock waits until a Get makes room.
+	FullBlock
+)
+
+func (p *pool[T]) overflow(e entry[T]) {
+	action := FullDiscard
+	if p.onFull != nil {
+		action = p.onFull(e.v)
+	}
+
+	switch action {
+	case FullEvict:
+		if !p.evict(e) {
+			p.discardFull(e)
+		}
+	case FullBlock:
+		p.c <- e
+		p.buffered(e.v)
+	default:
+		p.discardFull(e)
 	}
 }
 
+// maxEvictAttempts bounds how often evict retries when concurrent Puts take the room it made.
+const maxEvictAttempts = 8
+
+// evict discards the oldest buffered instance to make room for e,
+// and reports if e was put in the buffer.
+func (p *pool[T]) evict(e entry[T]) bool {
+	if cap(p.c) == 0 {
+		return false
+	}
+
+	for i := 0; i < maxEvictAttempts; i++ {
+		select {
+		case old := <-p.c:
+			p.maybeClose(old.v)
+		default:
+		}
+
+		select {
+		case p.c <- e:
+			p.buffered(e.v)
+			return true
+		default:
+			// Another Put took the room.
+		}
+	}
+	return false
+}
+
+// discardFull discards e because the Pool is full.
+func (p *pool[T]) discardFull(e entry[T]) {
+	if p.traceDiscards {
+		p.maybeError(newDiscardTrace())
+	}
+	p.maybeClose(e.v)
+}
+
 func (p *pool[T]) Close() *sync.WaitGroup {
+	p.shutdown(p.maybeClose)
+	return &p.wg
+}
+
+// shutdown closes the Pool, passing each buffered instance to discard.
+func (p *pool[T]) shutdown(discard func(T)) {
+	var zero T
+	p.emit(EventClose, zero, nil)
+
+	if p.stopValidate != nil {
+		close(p.stopValidate)
+		<-p.validateDone
+	}
+
+	atomic.StoreInt32(&p.closed, 1)
+	for atomic.LoadInt32(&p.putters) > 0 {
+		runtime.Gosched()
+	}
 	close(p.c)
 
-	for v := range p.c {
-		p.maybeClose(v)
+	for e := range p.c {
+		discard(e.v)
 	}
+}
+
+func (p *pool[T]) RecloseBuffered(closeFunc func(T)) *sync.WaitGroup {
+	p.close.Store(closeFunc)
 
+	// Only the snapshot, so concurrent Puts can't keep this going.
+	for n := len(p.c); n > 0; n-- {
+		select {
+		case e, ok := <-p.c:
+			if !ok {
+				return &p.wg
+			}
+			p.maybeClose(e.v)
+		default:
+			return &p.wg
+		}
+	}
 	return &p.wg
 }
 
@@ -92,14 +642,211 @@ type Options[T any] struct {
 	// This can be when the Pool is full or when Pool.Close() is called.
 	// CloseFunc is called from seperate Go routines, so it must be concurrency safe.
 	CloseFunc func(intance T)
+
+	// If larger than 0, each CloseFunc call is given CloseTimeout to return.
+	// When it doesn't, the call is abandoned and ErrCloseTimeout is passed to ErrorFunc.
+	// An abandoned call keeps running in the background,
+	// so the instance it was closing may leak.
+	CloseTimeout time.Duration
+
+	// If larger than 0, CloseFunc is called at most CloseRate times per second.
+	// Discarded instances wait their turn in their Go routine,
+	// so a large Close takes longer before its WaitGroup is done.
+	// This protects a backend from a burst of teardowns.
+	CloseRate float64
+
+	// If not nil, ErrorFunc is called for problems the Pool
+	// can't return to the caller, such as ErrCloseTimeout.
+	// It may be called from separate Go routines, so it must be concurrency safe.
+	ErrorFunc func(err error)
+
+	// If not nil, OnDrained is called when a Get takes the last buffered instance,
+	// meaning Gets are outpacing Puts.
+	// It is edge-triggered: after firing, it is not called again until
+	// Puts have refilled the Pool to at least half its size.
+	// OnDrained is called from the Go routine calling Get, so it should return quickly.
+	OnDrained func()
+
+	// WarnOnZeroReturn makes Get pass ErrZeroReturn to ErrorFunc
+	// each time it returns the zero value because the Pool was empty and NewFunc is nil.
+	// This is a development aid to detect a missing NewFunc,
+	// for example when T is an interface type. It is not meant for production hot paths.
+	WarnOnZeroReturn bool
+
+	// If not nil, RetainAfterReset is called by Pools from NewResetterPool
+	// on each instance passed to Put, after its Reset() method.
+	// When it returns false, the instance is discarded instead of pooled,
+	// for example to drop buffers that have grown too large.
+	// Other Pools ignore it.
+	RetainAfterReset func(instance T) bool
+
+	// DetectDuplicateNew makes the Pool remember the last few instances returned by NewFunc,
+	// and pass ErrDuplicateNew to ErrorFunc when NewFunc returns one of them again,
+	// for example a shared singleton.
+	// Instances are compared by identity, so only pointers and channels are tracked.
+	// Other instances, such as value types and structs, are not.
+	// This is a development aid: tracking takes a lock on each NewFunc call.
+	DetectDuplicateNew bool
+
+	// CoalesceCreates makes only one Get at a time call NewFunc.
+	// Other Gets that find the Pool empty wait until either an instance is Put,
+	// or it is their turn to call NewFunc.
+	// For fungible resources, this avoids a burst of creations when many Gets miss at once.
+	// Genuine demand for N instances still creates N, one after another,
+	// so a Get may wait for several NewFunc calls.
+	CoalesceCreates bool
+
+	// If not nil, each NewFunc call takes a permit from Budget,
+	// and each discarded instance gives it back after CloseFunc returns,
+	// or when the call is abandoned after CloseTimeout.
+	// Any Pool sharing the Budget gives back the permit of an instance it discards,
+	// so instances may move between Pools, for example across a SupervisedPool Restart.
+	// Share a Budget between Pools to cap their total amount of live instances.
+	// When Budget is exhausted, Get blocks until a permit is given back.
+	Budget *Budget
+
+	// BudgetFailFast makes Get not block on an exhausted Budget.
+	// Instead, ErrBudgetExhausted is passed to ErrorFunc
+	// and Get returns the zero value of T, like an empty Pool without NewFunc.
+	BudgetFailFast bool
+
+	// If not nil, OnFullFunc is called when Put finds the Pool full,
+	// to decide per instance what to do with it.
+	// For example, evict a buffered instance to keep an expensive one,
+	// but discard a cheap one. When nil, every instance is discarded.
+	// It runs in the Go routine calling Put, only on overflow.
+	// With FullBlock, Put waits for a Get; Close must not be called while a Put waits.
+	OnFullFunc func(instance T) FullAction
+
+	// If larger than 0, Put on a full Pool waits up to PutGrace for a Get to make room,
+	// before the instance overflows to OnFullFunc or is discarded.
+	// This avoids discards during brief spikes, at the cost of latency for the Put caller.
+	// The default of 0 overflows immediately.
+	PutGrace time.Duration
+
+	// TraceDiscards makes Put pass a *DiscardTrace to ErrorFunc
+	// each time it discards an instance because the Pool is full,
+	// to find the code paths creating more instances than the Pool can hold.
+	// Capturing the stack with runtime.Callers is expensive,
+	// so this is meant for debugging only.
+	TraceDiscards bool
+
+	// ZeroOnReset makes Pools from NewResetterPool, when T is a pointer to a struct,
+	// also set the pointer, slice, map, channel, func and interface fields of the struct
+	// to nil after Reset() and before RetainAfterReset.
+	// This breaks references a faulty Reset leaves behind, which would otherwise
+	// keep a large object graph alive while the instance is pooled.
+	// Fields tagged `gpool:"keep"`, such as a buffer Reset truncates for reuse, are left alone.
+	// Only direct fields are zeroed, using reflection on each Put.
+	// Other Pools ignore it.
+	ZeroOnReset bool
+
+	// Validators are run in order by Get on each buffered instance, before handing it out.
+	// The first error discards the instance through CloseFunc,
+	// passing a ValidationError carrying that error to ErrorFunc,
+	// and Get tries the next buffered instance or falls back to NewFunc.
+	// Put cheap checks first, so expensive ones only run on instances that pass them.
+	// Instances from NewFunc are not checked.
+	Validators []func(instance T) error
+
+	// If larger than 0, MaxValidateAttempts is the amount of buffered instances
+	// a single Get discards for failing validation, before it calls NewFunc directly.
+	// This bounds the latency of Get when many buffered instances went stale at once,
+	// at the cost of leaving the other stale instances to be found by later Gets.
+	// The default of 0 tries buffered instances until one passes or the Pool is empty.
+	MaxValidateAttempts int
+
+	// If larger than 0, a background Go routine checks the oldest buffered instance
+	// with Validators every BackgroundValidateInterval, and discards it if it fails,
+	// so stale instances are culled without adding latency to Get.
+	// An instance that passes goes back to the end of the buffer.
+	// While it is being checked, a Get may miss it and call NewFunc.
+	// The Go routine stops on Close, which waits for a running check to finish.
+	// It is not started when there are no Validators.
+	BackgroundValidateInterval time.Duration
+
+	// If not nil, Events receives an Event for each Get, Put, creation and discard,
+	// and on Close, failed validations, errors and drains, as a single stream
+	// for logging, metrics or tracing.
+	// Sends never block: when the consumer is not ready, the Event is dropped,
+	// so give the channel a buffer if it needs to see bursts.
+	// Events from concurrent callers may arrive out of order.
+	// When Events is nil, no Event is constructed. When set, each operation costs
+	// a copy of the instance and a non-blocking send, which is measurable on hot paths.
+	// The channel must not be closed while the Pool is in use.
+	Events chan<- Event[T]
+
+	// If not nil, RecordHold is called with the time an instance was checked out,
+	// from its Get until it is returned, for example to find slow consumers.
+	// Plain Get and Put can't match a returned instance to its Get,
+	// so only ContextPool uses it, for instances from GetContext,
+	// GetWithDeadline and GetWithHardDeadline. Other Pools ignore it.
+	// It is called from the Go routine returning the instance.
+	RecordHold func(held time.Duration)
 }
 
 // NewPool that can hold "size" amount of instances of T.
 func NewPool[T any](size int, opt Options[T]) Pool[T] {
+	return newPool(size, opt)
+}
+
+// NewPoolPerCPU returns a Pool that can hold "multiplier * GOMAXPROCS" amount of instances of T.
+// GOMAXPROCS is read once, so later changes don't affect the size of the Pool.
+func NewPoolPerCPU[T any](multiplier int, opt Options[T]) Pool[T] {
+	return NewPool(multiplier*runtime.GOMAXPROCS(0), opt)
+}
+
+// NewNestedPool returns a Pool of Pools, such as worker contexts that each own a buffer Pool.
+// opt.NewFunc creates the inner Pools. When an inner Pool is discarded,
+// it is closed and waited for, before opt.CloseFunc is called on it if that is not nil.
+// So the WaitGroup returned by Close on the outer Pool is only done
+// when all CloseFunc calls of the discarded inner Pools are too.
+func NewNestedPool[T any](size int, opt Options[Pool[T]]) Pool[Pool[T]] {
+	closeFunc := opt.CloseFunc
+	opt.CloseFunc = func(inner Pool[T]) {
+		inner.Close().Wait()
+		if closeFunc != nil {
+			closeFunc(inner)
+		}
+	}
+	return NewPool(size, opt)
+}
+
+func newPool[T any](size int, opt Options[T]) *pool[T] {
 	p := &pool[T]{
-		c:     make(chan T, size),
-		new:   opt.NewFunc,
-		close: opt.CloseFunc,
+		c:              make(chan entry[T], size),
+		new:            opt.NewFunc,
+		closeTimeout:   opt.CloseTimeout,
+		errFunc:        opt.ErrorFunc,
+		warnZero:       opt.WarnOnZeroReturn,
+		onDrained:      opt.OnDrained,
+		budget:         opt.Budget,
+		budgetFailFast: opt.BudgetFailFast,
+		onFull:         opt.OnFullFunc,
+		putGrace:       opt.PutGrace,
+		traceDiscards:  opt.TraceDiscards,
+		events:         opt.Events,
+		maxValidate:    opt.MaxValidateAttempts,
+	}
+
+	p.close.Store(opt.CloseFunc)
+
+	if len(opt.Validators) > 0 {
+		p.validators = append([]func(T) error(nil), opt.Validators...)
+	}
+	if opt.DetectDuplicateNew {
+		p.dups = new(dupDetector)
+	}
+	if opt.CoalesceCreates {
+		p.creating = make(chan struct{}, 1)
+	}
+	if opt.CloseRate > 0 {
+		p.closeEvery = time.Duration(float64(time.Second) / opt.CloseRate)
+	}
+	if opt.BackgroundValidateInterval > 0 && len(p.validators) > 0 {
+		p.stopValidate = make(chan struct{})
+		p.validateDone = make(chan struct{})
+		go p.validateBuffered(opt.BackgroundValidateInterval)
 	}
 
 	return p
@@ -111,18 +858,159 @@ type Resetter interface {
 	Reset()
 }
 
-type resetPool[T Resetter] struct {
-	Pool[T]
+type resetPool[T any] struct {
+	*pool[T]
+	reset  func(T)
+	retain func(T) bool
+	zero   []int // Field indices for ZeroOnReset.
+}
+
+func newResetPool[T any](size int, opt Options[T], reset func(T)) *resetPool[T] {
+	p := &resetPool[T]{
+		pool:   newPool(size, opt),
+		reset:  reset,
+		retain: opt.RetainAfterReset,
+	}
+	if opt.ZeroOnReset {
+		p.zero = zeroFields[T]()
+	}
+	return p
+}
+
+// resetOrDiscard resets v and reports if it should be pooled.
+func (p *resetPool[T]) resetOrDiscard(v T) bool {
+	p.reset(v)
+	if p.zero != nil {
+		zeroOut(v, p.zero)
+	}
+	if p.retain != nil && !p.retain(v) {
+		p.maybeClose(v)
+		return false
+	}
+	return true
 }
 
 func (p *resetPool[T]) Put(v T) {
-	v.Reset()
-	p.Pool.Put(v)
+	if p.resetOrDiscard(v) {
+		p.pool.Put(v)
+	}
+}
+
+func (p *resetPool[T]) BeginPut(v T) PutTxn {
+	return newPutTxn(v, p.Put, p.maybeClose, p.leakFunc())
+}
+
+func (p *resetPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	if p.resetOrDiscard(v) {
+		p.pool.PutWithDeferred(v, onNextGet)
+	}
 }
 
 // NewResetterPool returns a Pool for types that impelement the Ressetter interface.
 // Each intance passed to Pool.Put() has its Reset() method called.
 func NewResetterPool[T Resetter](size int, opt Options[T]) Pool[T] {
-	p := NewPool(size, opt)
-	return &resetPool[T]{p}
+	return newResetPool(size, opt, func(v T) { v.Reset() })
+}
+
+// NewResetterPoolPtr is like NewResetterPool, for types that implement
+// Resetter with a pointer receiver.
+// The Pool holds *T, and NewFunc defaults to new(T) if it is nil:
+//
+//	p := NewResetterPoolPtr[bytes.Buffer](10, Options[*bytes.Buffer]{})
+func NewResetterPoolPtr[T any, PT interface {
+	*T
+	Resetter
+}](size int, opt Options[PT]) Pool[PT] {
+	if opt.NewFunc == nil {
+		opt.NewFunc = func() PT { return PT(new(T)) }
+	}
+	return NewResetterPool(size, opt)
+}
+
+// AssertResetter does nothing, but only compiles if T implements Resetter.
+// It can be called from tests to check that the intended type,
+// for example the pointer rather than the value, is accepted by NewResetterPool.
+func AssertResetter[T Resetter]() {}
+
+// Healther is a type that can report its own health,
+// such as a connection that knows it was closed by the peer.
+type Healther interface {
+	Healthy() bool
+}
+
+// NewHealthCheckedPool returns a Pool for types that implement the Healther interface.
+// Get calls Healthy() on each buffered instance before handing it out.
+// Unhealthy instances are discarded through CloseFunc and Get tries the next,
+// until it finds a healthy one or falls back to NewFunc.
+// Each discard passes a ValidationError with ErrUnhealthy to ErrorFunc.
+// Healthy() runs before Options.Validators. Instances from NewFunc are not checked.
+func NewHealthCheckedPool[T Healther](size int, opt Options[T]) Pool[T] {
+	opt.Validators = append([]func(T) error{func(v T) error {
+		if !v.Healthy() {
+			return ErrUnhealthy
+		}
+		return nil
+	}}, opt.Validators...)
+	return newPool(size, opt)
+}
+
+// Validator is a type that can check itself and report why it is not usable,
+// giving more detail than a Healther.
+type Validator interface {
+	Validate() error
+}
+
+// NewValidatedPool returns a Pool for types that implement the Validator interface.
+// Get calls Validate() on each buffered instance before handing it out.
+// Instances that return an error are discarded through CloseFunc and Get tries the next,
+// until it finds a valid one or falls back to NewFunc.
+// Each discard passes a ValidationError carrying the error to ErrorFunc.
+// Validate() runs before Options.Validators. Instances from NewFunc are not checked.
+func NewValidatedPool[T Validator](size int, opt Options[T]) Pool[T] {
+	opt.Validators = append([]func(T) error{func(v T) error { return v.Validate() }}, opt.Validators...)
+	return newPool(size, opt)
+}
+
+// Acquirer is a type with its own checkout protocol,
+// such as a leased slot that must be claimed before use.
+type Acquirer interface {
+	// Acquire is called before the instance is handed out.
+	Acquire() error
+
+	// ReleaseResource is called when the instance is returned.
+	ReleaseResource()
+}
+
+type acquirerPool[T Acquirer] struct {
+	*pool[T]
+}
+
+func (p *acquirerPool[T]) Put(v T) {
+	v.ReleaseResource()
+	p.pool.Put(v)
+}
+
+func (p *acquirerPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	v.ReleaseResource()
+	p.pool.PutWithDeferred(v, onNextGet)
+}
+
+func (p *acquirerPool[T]) BeginPut(v T) PutTxn {
+	v.ReleaseResource()
+	return p.pool.BeginPut(v)
+}
+
+// NewAcquirerPool returns a Pool for types that implement the Acquirer interface.
+// Get calls Acquire() on each instance before handing it out,
+// and Put calls ReleaseResource() before pooling it.
+// Instances whose Acquire fails are discarded through CloseFunc,
+// passing a ValidationError with the error to ErrorFunc.
+// For buffered instances, Acquire() runs after Options.Validators passed.
+// Background validation only runs the Validators, never Acquire().
+// Get then tries the next buffered instance. When an instance from NewFunc fails,
+// Get returns the zero value of T and GetOK reports false, like an empty Pool without NewFunc.
+func NewAcquirerPool[T Acquirer](size int, opt Options[T]) Pool[T] {
+	p := newPool(size, opt)
+	p.acquire = func(v T) error { return v.Acquire() }
+	return &acquirerPool[T]{p}
 }
@@ -2,6 +2,12 @@ package gpool
 
 import (
 	"bytes"
+	"errors"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -37,6 +43,25 @@ func TestPool_maybeNew(t *testing.T) {
 	}
 }
 
+func TestPool_WarnOnZeroReturn(t *testing.T) {
+	var errs []error
+	p := NewPool(1, Options[error]{
+		WarnOnZeroReturn: true,
+		ErrorFunc:        func(err error) { errs = append(errs, err) },
+	})
+
+	p.Put(ErrCloseTimeout)
+	p.Get()
+	if len(errs) != 0 {
+		t.Fatalf("ErrorFunc called on a buffered instance: %v", errs)
+	}
+
+	p.Get()
+	if len(errs) != 1 || errs[0] != ErrZeroReturn {
+		t.Errorf("ErrorFunc: errs = %v, want [%v]", errs, ErrZeroReturn)
+	}
+}
+
 // check if a channel is closed within 1 second
 func checkClosed(c chan struct{}) bool {
 	tc := time.After(time.Second)
@@ -149,3 +174,684 @@ func Test_resetPool(t *testing.T) {
 
 	}
 }
+
+func TestPool_putAfterClose(t *testing.T) {
+	var closed int32
+	p := NewPool(1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(*int) { atomic.AddInt32(&closed, 1) },
+	})
+
+	v := p.Get()
+	wg := p.Close()
+	p.Put(v)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&closed); n != 1 {
+		t.Errorf("Pool.Put(): after Close, CloseFunc called %d times, want %d", n, 1)
+	}
+}
+
+func TestPool_CloseTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	errs := make(chan error, 1)
+
+	p := NewPool(1, Options[int]{
+		CloseFunc:    func(int) { <-block },
+		CloseTimeout: 10 * time.Millisecond,
+		ErrorFunc:    func(err error) { errs <- err },
+	})
+	p.Put(1)
+
+	done := make(chan struct{})
+	go func() {
+		p.Close().Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pool.Close(): Wait() blocked on a hanging CloseFunc")
+	}
+
+	if err := <-errs; err != ErrCloseTimeout {
+		t.Errorf("ErrorFunc: err = %v, want %v", err, ErrCloseTimeout)
+	}
+}
+
+func TestPool_PutWithDeferred(t *testing.T) {
+	p := NewPool(1, Options[*int]{
+		NewFunc: func() *int { return new(int) },
+	})
+
+	var calls int
+	v := p.Get()
+	p.(DeferredPutter[*int]).PutWithDeferred(v, func(w *int) {
+		if w != v {
+			t.Errorf("onNextGet: instance = %p, want %p", w, v)
+		}
+		calls++
+	})
+
+	p.Put(p.Get())
+	p.Get()
+
+	if calls != 1 {
+		t.Errorf("onNextGet called %d times, want 1", calls)
+	}
+}
+
+func TestNewResetterPoolPtr(t *testing.T) {
+	AssertResetter[*bytes.Buffer]()
+
+	p := NewResetterPoolPtr[bytes.Buffer](1, Options[*bytes.Buffer]{})
+
+	b := p.Get()
+	if b == nil {
+		t.Fatal("NewResetterPoolPtr(): default NewFunc returned nil")
+	}
+	b.WriteString("hello")
+	p.Put(b)
+
+	if b = p.Get(); b.Len() != 0 {
+		t.Errorf("resetPool.Get(): len = %d, want %d", b.Len(), 0)
+	}
+}
+
+func TestPool_OnDrained(t *testing.T) {
+	var calls int
+	p := NewPool(4, Options[int]{
+		OnDrained: func() { calls++ },
+	})
+
+	cycle := func(n int) {
+		for i := 0; i < n; i++ {
+			p.Put(i)
+		}
+		for i := 0; i < n; i++ {
+			p.Get()
+		}
+	}
+
+	tests := []struct {
+		name  string
+		n     int
+		calls int
+	}{
+		{"drain full pool", 4, 1},
+		{"below half, not re-armed", 1, 1},
+		{"half, re-armed", 2, 2},
+	}
+
+	for _, tt := range tests {
+		cycle(tt.n)
+		if calls != tt.calls {
+			t.Errorf("%s: OnDrained called %d times, want %d", tt.name, calls, tt.calls)
+		}
+	}
+}
+
+func Test_resetPool_RetainAfterReset(t *testing.T) {
+	const maxCap = 64
+
+	closed := make(chan *bytes.Buffer, 1)
+	p := NewResetterPool(10, Options[*bytes.Buffer]{
+		NewFunc:   func() *bytes.Buffer { return new(bytes.Buffer) },
+		CloseFunc: func(b *bytes.Buffer) { closed <- b },
+		RetainAfterReset: func(b *bytes.Buffer) bool {
+			if b.Len() != 0 {
+				t.Error("RetainAfterReset called before Reset")
+			}
+			return b.Cap() <= maxCap
+		},
+	})
+
+	small, large := p.Get(), p.Get()
+	small.WriteString("hello")
+	large.Write(make([]byte, maxCap*2))
+	p.Put(small)
+	p.Put(large)
+
+	if b := <-closed; b != large {
+		t.Error("resetPool.Put(): large buffer not discarded")
+	}
+	if b := p.Get(); b != small {
+		t.Error("resetPool.Put(): small buffer not retained")
+	}
+}
+
+func TestPool_concurrentCloseGet(t *testing.T) {
+	const size = 100
+
+	p := NewPool(size, Options[*int]{
+		NewFunc: func() *int { return new(int) },
+	})
+	for i := 0; i < size; i++ {
+		p.Put(new(int))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < size; j++ {
+				if p.Get() == nil {
+					t.Error("pool.Get() returned zero value during Close")
+					return
+				}
+			}
+		}()
+	}
+
+	p.Close().Wait()
+	wg.Wait()
+}
+
+func TestNewPoolPerCPU(t *testing.T) {
+	p := NewPoolPerCPU(2, Options[int]{}).(*pool[int])
+
+	if got, want := cap(p.c), 2*runtime.GOMAXPROCS(0); got != want {
+		t.Errorf("NewPoolPerCPU(): size = %d, want %d", got, want)
+	}
+}
+
+type healther struct {
+	healthy bool
+}
+
+func (h *healther) Healthy() bool { return h.healthy }
+
+func TestNewHealthCheckedPool(t *testing.T) {
+	closed := make(chan *healther, 2)
+	p := NewHealthCheckedPool(3, Options[*healther]{
+		NewFunc:   func() *healther { return &healther{true} },
+		CloseFunc: func(h *healther) { closed <- h },
+	})
+
+	good := &healther{true}
+	bad := []*healther{{false}, {false}}
+
+	p.Put(bad[0])
+	p.Put(bad[1])
+	p.Put(good)
+
+	if h := p.Get(); h != good {
+		t.Errorf("healthPool.Get() = %v, want %v", h, good)
+	}
+	p.Close().Wait()
+
+	for range bad {
+		if h := <-closed; !(h == bad[0] || h == bad[1]) {
+			t.Errorf("healthPool.Get(): closed %v, want unhealthy instance", h)
+		}
+	}
+
+	if h := p.Get(); h == nil || !h.Healthy() {
+		t.Errorf("healthPool.Get() = %v, want new instance", h)
+	}
+}
+
+func TestPool_CloseRate(t *testing.T) {
+	const (
+		n    = 5
+		rate = 100
+	)
+
+	p := NewPool(n, Options[int]{
+		CloseFunc: func(int) {},
+		CloseRate: rate,
+	})
+	for i := 0; i < n; i++ {
+		p.Put(i)
+	}
+
+	start := time.Now()
+	p.Close().Wait()
+
+	// The first call runs immediately, each next one a slot later.
+	if got, want := time.Since(start), (n-1)*time.Second/rate; got < want {
+		t.Errorf("pool.Close(): took %v, want at least %v", got, want)
+	}
+}
+
+type validator struct {
+	err error
+}
+
+func (v *validator) Validate() error { return v.err }
+
+func TestNewValidatedPool(t *testing.T) {
+	errBroken := errors.New("broken")
+
+	var errs []error
+	p := NewValidatedPool(2, Options[*validator]{
+		ErrorFunc: func(err error) { errs = append(errs, err) },
+	})
+
+	good := new(validator)
+	p.Put(&validator{errBroken})
+	p.Put(good)
+
+	if v := p.Get(); v != good {
+		t.Errorf("validatedPool.Get() = %v, want %v", v, good)
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("ErrorFunc called %d times, want 1", len(errs))
+	}
+	var verr *ValidationError
+	if !errors.As(errs[0], &verr) || !errors.Is(errs[0], errBroken) {
+		t.Errorf("ErrorFunc: err = %v, want ValidationError wrapping %v", errs[0], errBroken)
+	}
+}
+
+func TestPool_CoalesceCreates(t *testing.T) {
+	const getters = 10
+
+	var (
+		running int32
+		created int32
+		release = make(chan struct{})
+	)
+
+	p := NewPool(getters, Options[*int]{
+		NewFunc: func() *int {
+			if n := atomic.AddInt32(&running, 1); n > 1 {
+				t.Errorf("NewFunc: %d concurrent calls", n)
+			}
+			defer atomic.AddInt32(&running, -1)
+
+			if atomic.AddInt32(&created, 1) == 1 {
+				<-release
+			}
+			return new(int)
+		},
+		CoalesceCreates: true,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < getters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Put(p.Get())
+		}()
+	}
+
+	// Let the other Gets queue up behind the first create.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if created == getters {
+		t.Errorf("NewFunc called %d times, want fewer with reuse from Puts", created)
+	}
+}
+
+type acquirer struct {
+	err      error
+	acquired bool
+}
+
+func (a *acquirer) Acquire() error {
+	if a.err != nil {
+		return a.err
+	}
+	a.acquired = true
+	return nil
+}
+
+func (a *acquirer) ReleaseResource() { a.acquired = false }
+
+func TestNewAcquirerPool(t *testing.T) {
+	errTaken := errors.New("taken")
+
+	var (
+		errs   []error
+		newErr error
+		closed = make(chan *acquirer, 2)
+	)
+	p := NewAcquirerPool(2, Options[*acquirer]{
+		NewFunc:   func() *acquirer { return &acquirer{err: newErr} },
+		CloseFunc: func(a *acquirer) { closed <- a },
+		ErrorFunc: func(err error) { errs = append(errs, err) },
+	})
+
+	good := p.Get()
+	if good == nil || !good.acquired {
+		t.Fatalf("acquirerPool.Get() = %+v, want acquired new instance", good)
+	}
+	p.Put(good)
+	if good.acquired {
+		t.Error("acquirerPool.Put(): ReleaseResource not called")
+	}
+
+	bad := &acquirer{err: errTaken}
+	p.Put(bad)
+
+	if a := p.Get(); a != good || !a.acquired {
+		t.Errorf("acquirerPool.Get() = %+v, want acquired buffered instance", a)
+	}
+	if a := p.Get(); a == bad || !a.acquired {
+		t.Errorf("acquirerPool.Get() = %+v, want new instance after failed Acquire", a)
+	}
+	if a := <-closed; a != bad {
+		t.Errorf("acquirerPool.Get(): closed %+v, want %+v", a, bad)
+	}
+
+	newErr = errTaken
+	if a := p.Get(); a != nil {
+		t.Errorf("acquirerPool.Get() = %+v, want nil for failed new instance", a)
+	}
+	<-closed
+
+	if len(errs) != 2 || !errors.Is(errs[0], errTaken) || !errors.Is(errs[1], errTaken) {
+		t.Errorf("ErrorFunc: errs = %v, want 2x %v", errs, errTaken)
+	}
+}
+
+func TestPool_OnFullFunc(t *testing.T) {
+	closed := make(chan int, 2)
+	p := NewPool(1, Options[int]{
+		CloseFunc: func(v int) { closed <- v },
+		OnFullFunc: func(v int) FullAction {
+			return FullAction(v)
+		},
+	})
+
+	p.Put(-1)
+
+	p.Put(int(FullDiscard))
+	if v := <-closed; v != int(FullDiscard) {
+		t.Errorf("pool.Put(): FullDiscard closed %d, want %d", v, FullDiscard)
+	}
+
+	p.Put(int(FullEvict))
+	if v := <-closed; v != -1 {
+		t.Errorf("pool.Put(): FullEvict closed %d, want %d", v, -1)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Put(int(FullBlock))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("pool.Put(): FullBlock did not block")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if v := p.Get(); v != int(FullEvict) {
+		t.Errorf("pool.Get() = %d, want %d", v, FullEvict)
+	}
+	<-done
+	if v := p.Get(); v != int(FullBlock) {
+		t.Errorf("pool.Get() = %d, want %d", v, FullBlock)
+	}
+
+	t.Run("size 0", func(t *testing.T) {
+		closed := make(chan int, 1)
+		p := NewPool(0, Options[int]{
+			CloseFunc:  func(v int) { closed <- v },
+			OnFullFunc: func(int) FullAction { return FullEvict },
+		})
+
+		p.Put(1)
+		if v := <-closed; v != 1 {
+			t.Errorf("pool.Put(): FullEvict on size 0 closed %d, want %d", v, 1)
+		}
+	})
+}
+
+func TestPool_PutGrace(t *testing.T) {
+	closed := make(chan int, 1)
+	p := NewPool(1, Options[int]{
+		CloseFunc: func(v int) { closed <- v },
+		PutGrace:  50 * time.Millisecond,
+	})
+	p.Put(1)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		p.Get()
+	}()
+
+	p.Put(2)
+	if v := p.Get(); v != 2 {
+		t.Errorf("pool.Put(): instance not pooled within grace, Get() = %d", v)
+	}
+
+	p.Put(3)
+	start := time.Now()
+	p.Put(4)
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("pool.Put(): overflowed after %v, want at least the grace period", d)
+	}
+	if v := <-closed; v != 4 {
+		t.Errorf("pool.Put(): discarded %d, want %d", v, 4)
+	}
+}
+
+func TestNewNestedPool(t *testing.T) {
+	var closed int32
+	p := NewNestedPool(2, Options[Pool[*int]]{
+		NewFunc: func() Pool[*int] {
+			return NewPool(2, Options[*int]{
+				NewFunc: func() *int { return new(int) },
+				CloseFunc: func(*int) {
+					time.Sleep(time.Millisecond)
+					atomic.AddInt32(&closed, 1)
+				},
+			})
+		},
+	})
+
+	inners := []Pool[*int]{p.Get(), p.Get()}
+	for _, inner := range inners {
+		inner.Put(new(int))
+		inner.Put(new(int))
+		p.Put(inner)
+	}
+
+	p.Close().Wait()
+	if n := atomic.LoadInt32(&closed); n != 4 {
+		t.Errorf("nested Close().Wait(): %d inner instances closed, want %d", n, 4)
+	}
+}
+
+func TestPool_Validators(t *testing.T) {
+	errNegative := errors.New("negative")
+	var calls []string
+
+	var errs []error
+	p := NewPool(2, Options[int]{
+		Validators: []func(int) error{
+			func(v int) error {
+				calls = append(calls, "cheap")
+				if v < 0 {
+					return errNegative
+				}
+				return nil
+			},
+			func(int) error {
+				calls = append(calls, "expensive")
+				return nil
+			},
+		},
+		ErrorFunc: func(err error) { errs = append(errs, err) },
+	})
+
+	p.Put(-1)
+	p.Put(1)
+
+	if v := p.Get(); v != 1 {
+		t.Errorf("pool.Get() = %d, want %d", v, 1)
+	}
+
+	want := []string{"cheap", "cheap", "expensive"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("validators called %q, want %q", calls, want)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], errNegative) {
+		t.Errorf("ErrorFunc: errs = %v, want [%v]", errs, errNegative)
+	}
+}
+
+func TestNewHealthCheckedPool_Validators(t *testing.T) {
+	var called bool
+	p := NewHealthCheckedPool(1, Options[*healther]{
+		Validators: []func(*healther) error{
+			func(*healther) error {
+				called = true
+				return nil
+			},
+		},
+	})
+
+	p.Put(&healther{false})
+	p.Get()
+
+	if called {
+		t.Error("Validators called on an unhealthy instance")
+	}
+}
+
+func TestPool_GetOK(t *testing.T) {
+	p := NewPool(1, Options[int]{})
+	if v, ok := GetOK(p); ok || v != 0 {
+		t.Errorf("GetOK on empty Pool = %d, %t, want 0, false", v, ok)
+	}
+
+	p.Put(0)
+	if v, ok := GetOK(p); !ok || v != 0 {
+		t.Errorf("GetOK after Put(0) = %d, %t, want 0, true", v, ok)
+	}
+
+	p = NewPool(1, Options[int]{
+		NewFunc: func() int { return 0 },
+	})
+	if v, ok := GetOK(p); !ok || v != 0 {
+		t.Errorf("GetOK with NewFunc = %d, %t, want 0, true", v, ok)
+	}
+
+	p = NewPool(1, Options[int]{
+		NewFunc:        func() int { return 1 },
+		Budget:         NewBudget(0),
+		BudgetFailFast: true,
+	})
+	if v, ok := GetOK(p); ok || v != 0 {
+		t.Errorf("GetOK with exhausted Budget = %d, %t, want 0, false", v, ok)
+	}
+	// A Pool without GetOK can't tell, so GetOK reports true.
+	if v, ok := GetOK[int](minimalPool[int]{make(chan int)}); !ok || v != 0 {
+		t.Errorf("GetOK on minimalPool = %d, %t, want 0, true", v, ok)
+	}
+}
+
+func TestPool_RecloseBuffered(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		leaked []int
+		closed []int
+	)
+	p := NewPool(3, Options[int]{
+		CloseFunc: func(v int) {
+			mu.Lock()
+			leaked = append(leaked, v)
+			mu.Unlock()
+		},
+	})
+	p.Put(1)
+	p.Put(2)
+
+	p.(Recloser[int]).RecloseBuffered(func(v int) {
+		mu.Lock()
+		closed = append(closed, v)
+		mu.Unlock()
+	}).Wait()
+
+	if v, ok := GetOK(p); ok {
+		t.Errorf("GetOK after RecloseBuffered = %d, want empty Pool", v)
+	}
+
+	p.Put(3)
+	p.Close().Wait()
+
+	sort.Ints(closed)
+	if len(leaked) != 0 {
+		t.Errorf("old CloseFunc called with %v", leaked)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("new CloseFunc called with %v, want %v", closed, want)
+	}
+}
+
+func TestPool_MaxValidateAttempts(t *testing.T) {
+	var errs []error
+	p := NewPool(4, Options[int]{
+		NewFunc: func() int { return 7 },
+		Validators: []func(int) error{
+			func(v int) error {
+				if v < 0 {
+					return errors.New("negative")
+				}
+				return nil
+			},
+		},
+		ErrorFunc:           func(err error) { errs = append(errs, err) },
+		MaxValidateAttempts: 2,
+	})
+
+	for i := 0; i < 4; i++ {
+		p.Put(-1)
+	}
+
+	if v := p.Get(); v != 7 {
+		t.Errorf("pool.Get() = %d, want %d from NewFunc", v, 7)
+	}
+	if len(errs) != 2 {
+		t.Errorf("ErrorFunc: got %d errors, want %d", len(errs), 2)
+	}
+	if n := len(p.(*pool[int]).c); n != 2 {
+		t.Errorf("buffered = %d, want %d stale instances left", n, 2)
+	}
+}
+
+func TestPool_BackgroundValidateInterval(t *testing.T) {
+	closed := make(chan int, 2)
+	p := NewPool(2, Options[int]{
+		Validators: []func(int) error{
+			func(v int) error {
+				if v < 0 {
+					return errors.New("negative")
+				}
+				return nil
+			},
+		},
+		CloseFunc:                  func(v int) { closed <- v },
+		BackgroundValidateInterval: time.Millisecond,
+	})
+
+	p.Put(-1)
+	p.Put(1)
+
+	select {
+	case v := <-closed:
+		if v != -1 {
+			t.Errorf("CloseFunc(%d), want %d", v, -1)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("invalid instance not discarded in the background")
+	}
+
+	p.Close().Wait()
+	if v := <-closed; v != 1 {
+		t.Errorf("CloseFunc(%d) on Close, want %d", v, 1)
+	}
+}
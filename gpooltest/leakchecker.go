@@ -0,0 +1,99 @@
+// Package gpooltest provides helpers for testing code that uses gpool.
+package gpooltest
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/muhlemmer/gpool"
+)
+
+// LeakChecker wraps a Pool and counts the instances that are checked out.
+// Assert fails the test if any instance was not returned with Put.
+//
+// In table-driven tests, create a LeakChecker inside each sub-test,
+// so a leak is reported against the case that caused it:
+//
+//	for _, tt := range tests {
+//		t.Run(tt.name, func(t *testing.T) {
+//			p := gpooltest.NewLeakChecker(t, realPool)
+//			defer p.Assert()
+//			tt.run(p)
+//		})
+//	}
+type LeakChecker[T any] struct {
+	gpool.Pool[T]
+
+	tb          testing.TB
+	outstanding int64
+}
+
+// NewLeakChecker returns a LeakChecker for p, reporting to tb.
+func NewLeakChecker[T any](tb testing.TB, p gpool.Pool[T]) *LeakChecker[T] {
+	return &LeakChecker[T]{
+		Pool: p,
+		tb:   tb,
+	}
+}
+
+// Get is like GetOK, so a zero value from an empty Pool
+// without NewFunc is not counted as outstanding.
+func (c *LeakChecker[T]) Get() T {
+	v, _ := c.GetOK()
+	return v
+}
+
+// GetOK forwards through gpool.GetOK,
+// and only counts the instance as outstanding when ok is true.
+func (c *LeakChecker[T]) GetOK() (T, bool) {
+	v, ok := gpool.GetOK(c.Pool)
+	if ok {
+		atomic.AddInt64(&c.outstanding, 1)
+	}
+	return v, ok
+}
+
+func (c *LeakChecker[T]) Put(v T) {
+	atomic.AddInt64(&c.outstanding, -1)
+	c.Pool.Put(v)
+}
+
+// PutWithDeferred forwards through gpool.PutWithDeferred.
+func (c *LeakChecker[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	atomic.AddInt64(&c.outstanding, -1)
+	gpool.PutWithDeferred(c.Pool, v, onNextGet)
+}
+
+// BeginPut forwards through gpool.BeginPut.
+func (c *LeakChecker[T]) BeginPut(v T) gpool.PutTxn {
+	atomic.AddInt64(&c.outstanding, -1)
+	return gpool.BeginPut(c.Pool, v)
+}
+
+// RecloseBuffered forwards through gpool.RecloseBuffered.
+func (c *LeakChecker[T]) RecloseBuffered(closeFunc func(T)) *sync.WaitGroup {
+	return gpool.RecloseBuffered(c.Pool, closeFunc)
+}
+
+// CloseWithGroup forwards through gpool.CloseWithGroup.
+func (c *LeakChecker[T]) CloseWithGroup(g gpool.Grouper) {
+	gpool.CloseWithGroup(c.Pool, g)
+}
+
+// Outstanding returns the amount of instances currently checked out.
+func (c *LeakChecker[T]) Outstanding() int {
+	return int(atomic.LoadInt64(&c.outstanding))
+}
+
+// Assert fails the test if the amount of Gets and Puts doesn't match.
+func (c *LeakChecker[T]) Assert() {
+	c.tb.Helper()
+
+	switch n := c.Outstanding(); {
+	case n > 0:
+		c.tb.Errorf("gpooltest: %d instance(s) not returned to the Pool", n)
+	case n < 0:
+		c.tb.Errorf("gpooltest: %d more Put(s) than Get(s)", -n)
+	}
+}
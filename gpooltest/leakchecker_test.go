@@ -0,0 +1,67 @@
+package gpooltest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/muhlemmer/gpool"
+)
+
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestLeakChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		gets    int
+		puts    int
+		wantErr bool
+	}{
+		{"balanced", 3, 3, false},
+		{"leak", 3, 2, true},
+		{"extra put", 2, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := new(recorder)
+			p := NewLeakChecker(r, gpool.NewPool(10, gpool.Options[*int]{
+				NewFunc: func() *int { return new(int) },
+			}))
+
+			for i := 0; i < tt.gets; i++ {
+				p.Get()
+			}
+			for i := 0; i < tt.puts; i++ {
+				p.Put(new(int))
+			}
+
+			p.Assert()
+			if gotErr := len(r.errs) > 0; gotErr != tt.wantErr {
+				t.Errorf("LeakChecker.Assert() errors = %q, wantErr %v", r.errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLeakChecker_emptyGet(t *testing.T) {
+	r := new(recorder)
+	p := NewLeakChecker(r, gpool.NewPool(1, gpool.Options[*int]{}))
+
+	if v := p.Get(); v != nil {
+		t.Fatalf("LeakChecker.Get() = %v, want nil", v)
+	}
+
+	p.Assert()
+	if len(r.errs) > 0 {
+		t.Errorf("LeakChecker.Assert() errors = %q, want none for a nil Get", r.errs)
+	}
+}
@@ -0,0 +1,55 @@
+package gpool
+
+// Grouper runs functions in Go routines on behalf of the caller,
+// which collects their errors and waits for them.
+// *errgroup.Group from golang.org/x/sync/errgroup implements it,
+// without this package depending on it.
+type Grouper interface {
+	Go(f func() error)
+}
+
+// GroupCloser is a Pool that supports CloseWithGroup.
+// See Pool for how to check for it.
+type GroupCloser[T any] interface {
+	// CloseWithGroup is like Close, but runs each CloseFunc call through g.Go
+	// instead of a Go routine of the Pool, so the caller's Wait covers them
+	// and g can limit their concurrency.
+	// A call that exceeds CloseTimeout returns ErrCloseTimeout to g.
+	// Instances Put after CloseWithGroup are discarded like after Close,
+	// on the WaitGroup of the Pool.
+	CloseWithGroup(g Grouper)
+}
+
+// CloseWithGroup calls p.CloseWithGroup if p is a GroupCloser.
+// Otherwise it runs p.Close and waits for it in a single g.Go call.
+func CloseWithGroup[T any](p Pool[T], g Grouper) {
+	if c, ok := p.(GroupCloser[T]); ok {
+		c.CloseWithGroup(g)
+		return
+	}
+	g.Go(func() error {
+		p.Close().Wait()
+		return nil
+	})
+}
+
+func (p *pool[T]) CloseWithGroup(g Grouper) {
+	p.shutdown(p.groupDiscard(g))
+}
+
+func (p *priorityPool[T]) CloseWithGroup(g Grouper) {
+	p.shutdown(p.groupDiscard(g))
+}
+
+// groupDiscard returns a discard function that runs CloseFunc through g.
+func (p *pool[T]) groupDiscard(g Grouper) func(T) {
+	return func(v T) {
+		closeFunc := p.discarding(v)
+		if closeFunc == nil {
+			return
+		}
+		g.Go(func() error {
+			return p.runClose(closeFunc, v)
+		})
+	}
+}
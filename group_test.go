@@ -0,0 +1,103 @@
+package gpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testGroup is a Grouper like errgroup.Group, keeping all errors.
+type testGroup struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	gos  int
+	errs []error
+}
+
+func (g *testGroup) Go(f func() error) {
+	g.mu.Lock()
+	g.gos++
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *testGroup) Wait() []error {
+	g.wg.Wait()
+	return g.errs
+}
+
+func TestPool_CloseWithGroup(t *testing.T) {
+	var closed int32
+	opt := Options[*int]{
+		CloseFunc: func(*int) { atomic.AddInt32(&closed, 1) },
+	}
+
+	tests := []struct {
+		name string
+		pool Pool[*int]
+	}{
+		{"pool", NewPool(3, opt)},
+		{"priority", NewPriorityPool(3, opt)},
+		{"metrics", Chain(NewPool(3, opt), WithMetrics[*int](new(Metrics)))},
+		{"supervised", NewSupervisedPool(func() Pool[*int] { return NewPool(3, opt) })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&closed, 0)
+			for i := 0; i < 3; i++ {
+				tt.pool.Put(new(int))
+			}
+
+			g := new(testGroup)
+			CloseWithGroup(tt.pool, g)
+			if errs := g.Wait(); len(errs) > 0 {
+				t.Errorf("CloseWithGroup(): errors %v", errs)
+			}
+
+			if g.gos != 3 {
+				t.Errorf("CloseWithGroup(): %d calls to Go, want %d", g.gos, 3)
+			}
+			if n := atomic.LoadInt32(&closed); n != 3 {
+				t.Errorf("CloseWithGroup(): CloseFunc called %d times, want %d", n, 3)
+			}
+		})
+	}
+}
+
+func TestPool_CloseWithGroup_timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	p := NewPool(1, Options[*int]{
+		CloseFunc:    func(*int) { <-release },
+		CloseTimeout: time.Millisecond,
+	})
+	p.Put(new(int))
+
+	g := new(testGroup)
+	CloseWithGroup(p, g)
+
+	if errs := g.Wait(); len(errs) != 1 || errs[0] != ErrCloseTimeout {
+		t.Errorf("CloseWithGroup(): errors %v, want %v", errs, ErrCloseTimeout)
+	}
+}
+
+func TestCloseWithGroup_minimalPool(t *testing.T) {
+	g := new(testGroup)
+	CloseWithGroup[int](minimalPool[int]{make(chan int, 1)}, g)
+	g.Wait()
+
+	if g.gos != 1 {
+		t.Errorf("CloseWithGroup(): %d calls to Go, want %d", g.gos, 1)
+	}
+}
@@ -0,0 +1,220 @@
+package gpool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Middleware wraps a Pool to add behaviour to its operations.
+type Middleware[T any] func(Pool[T]) Pool[T]
+
+// Chain wraps p with mws.
+// The first Middleware is the outermost: its operations run first.
+// So Chain(p, a, b) is a(b(p)).
+func Chain[T any](p Pool[T], mws ...Middleware[T]) Pool[T] {
+	for i := len(mws) - 1; i >= 0; i-- {
+		p = mws[i](p)
+	}
+	return p
+}
+
+// Metrics counts the operations passing a WithMetrics Middleware.
+// The zero value is ready to use.
+type Metrics struct {
+	gets     int64
+	puts     int64
+	discards int64
+}
+
+// Gets returns the amount of Get calls.
+func (m *Metrics) Gets() int64 { return atomic.LoadInt64(&m.gets) }
+
+// Puts returns the amount of instances put back in the Pool,
+// through Put, PutWithDeferred or a committed PutTxn.
+func (m *Metrics) Puts() int64 { return atomic.LoadInt64(&m.puts) }
+
+// Discards returns the amount of instances discarded by an aborted PutTxn.
+func (m *Metrics) Discards() int64 { return atomic.LoadInt64(&m.discards) }
+
+// Outstanding returns the amount of instances Got but not yet returned.
+func (m *Metrics) Outstanding() int64 { return m.Gets() - m.Puts() - m.Discards() }
+
+// WithMetrics counts operations into m.
+func WithMetrics[T any](m *Metrics) Middleware[T] {
+	return func(p Pool[T]) Pool[T] {
+		return &metricsPool[T]{p, m}
+	}
+}
+
+type metricsPool[T any] struct {
+	Pool[T]
+	m *Metrics
+}
+
+func (p *metricsPool[T]) Get() T {
+	atomic.AddInt64(&p.m.gets, 1)
+	return p.Pool.Get()
+}
+
+func (p *metricsPool[T]) GetOK() (T, bool) {
+	atomic.AddInt64(&p.m.gets, 1)
+	return GetOK(p.Pool)
+}
+
+func (p *metricsPool[T]) Put(v T) {
+	atomic.AddInt64(&p.m.puts, 1)
+	p.Pool.Put(v)
+}
+
+func (p *metricsPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	atomic.AddInt64(&p.m.puts, 1)
+	PutWithDeferred(p.Pool, v, onNextGet)
+}
+
+func (p *metricsPool[T]) BeginPut(v T) PutTxn {
+	return &metricsTxn{BeginPut(p.Pool, v), p.m, 0}
+}
+
+func (p *metricsPool[T]) RecloseBuffered(closeFunc func(T)) *sync.WaitGroup {
+	return RecloseBuffered(p.Pool, closeFunc)
+}
+
+func (p *metricsPool[T]) CloseWithGroup(g Grouper) {
+	CloseWithGroup(p.Pool, g)
+}
+
+type metricsTxn struct {
+	PutTxn
+	m    *Metrics
+	done int32
+}
+
+func (t *metricsTxn) Commit() {
+	if atomic.CompareAndSwapInt32(&t.done, 0, 1) {
+		atomic.AddInt64(&t.m.puts, 1)
+	}
+	t.PutTxn.Commit()
+}
+
+func (t *metricsTxn) Abort() {
+	if atomic.CompareAndSwapInt32(&t.done, 0, 1) {
+		atomic.AddInt64(&t.m.discards, 1)
+	}
+	t.PutTxn.Abort()
+}
+
+// WithLogging passes each operation and its instance to logf,
+// such as log.Printf.
+func WithLogging[T any](logf func(format string, args ...any)) Middleware[T] {
+	return func(p Pool[T]) Pool[T] {
+		return &loggingPool[T]{p, logf}
+	}
+}
+
+type loggingPool[T any] struct {
+	Pool[T]
+	logf func(format string, args ...any)
+}
+
+func (p *loggingPool[T]) Get() T {
+	v := p.Pool.Get()
+	p.logf("gpool: Get %v", v)
+	return v
+}
+
+func (p *loggingPool[T]) GetOK() (T, bool) {
+	v, ok := GetOK(p.Pool)
+	p.logf("gpool: GetOK %v %t", v, ok)
+	return v, ok
+}
+
+func (p *loggingPool[T]) Put(v T) {
+	p.logf("gpool: Put %v", v)
+	p.Pool.Put(v)
+}
+
+func (p *loggingPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	p.logf("gpool: PutWithDeferred %v", v)
+	PutWithDeferred(p.Pool, v, onNextGet)
+}
+
+func (p *loggingPool[T]) BeginPut(v T) PutTxn {
+	p.logf("gpool: BeginPut %v", v)
+	return BeginPut(p.Pool, v)
+}
+
+func (p *loggingPool[T]) Close() *sync.WaitGroup {
+	p.logf("gpool: Close")
+	return p.Pool.Close()
+}
+
+func (p *loggingPool[T]) RecloseBuffered(closeFunc func(T)) *sync.WaitGroup {
+	p.logf("gpool: RecloseBuffered")
+	return RecloseBuffered(p.Pool, closeFunc)
+}
+
+func (p *loggingPool[T]) CloseWithGroup(g Grouper) {
+	p.logf("gpool: CloseWithGroup")
+	CloseWithGroup(p.Pool, g)
+}
+
+// WithValidation checks instances with validate when they are put back.
+// Instances that fail are discarded through CloseFunc instead of pooled,
+// so they are never handed out again.
+// Use NewHealthCheckedPool to check instances on Get instead.
+func WithValidation[T any](validate func(T) bool) Middleware[T] {
+	return func(p Pool[T]) Pool[T] {
+		return &validationPool[T]{p, validate}
+	}
+}
+
+type validationPool[T any] struct {
+	Pool[T]
+	validate func(T) bool
+}
+
+func (p *validationPool[T]) GetOK() (T, bool) {
+	return GetOK(p.Pool)
+}
+
+func (p *validationPool[T]) Put(v T) {
+	if !p.validate(v) {
+		discard(p.Pool, v)
+		return
+	}
+	p.Pool.Put(v)
+}
+
+func (p *validationPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	if !p.validate(v) {
+		discard(p.Pool, v)
+		return
+	}
+	PutWithDeferred(p.Pool, v, onNextGet)
+}
+
+func (p *validationPool[T]) BeginPut(v T) PutTxn {
+	return &validationTxn[T]{BeginPut(p.Pool, v), v, p.validate}
+}
+
+func (p *validationPool[T]) RecloseBuffered(closeFunc func(T)) *sync.WaitGroup {
+	return RecloseBuffered(p.Pool, closeFunc)
+}
+
+func (p *validationPool[T]) CloseWithGroup(g Grouper) {
+	CloseWithGroup(p.Pool, g)
+}
+
+type validationTxn[T any] struct {
+	PutTxn
+	v        T
+	validate func(T) bool
+}
+
+func (t *validationTxn[T]) Commit() {
+	if !t.validate(t.v) {
+		t.PutTxn.Abort()
+		return
+	}
+	t.PutTxn.Commit()
+}
@@ -0,0 +1,117 @@
+package gpool
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware[int] {
+		return WithLogging[int](func(format string, args ...any) {
+			order = append(order, name+" "+fmt.Sprintf(format, args...))
+		})
+	}
+
+	p := Chain(NewPool(1, Options[int]{}), mark("outer"), mark("inner"))
+	p.Put(1)
+
+	want := []string{"outer gpool: Put 1", "inner gpool: Put 1"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("Chain(): order = %q, want %q", order, want)
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	var m Metrics
+	p := Chain(NewPool(2, Options[int]{}), WithMetrics[int](&m))
+
+	p.Get()
+	p.Get()
+	p.Get()
+	p.Put(1)
+	txn := p.(TxnPutter[int]).BeginPut(2)
+	txn.Abort()
+	txn.Abort()
+
+	if got := [4]int64{m.Gets(), m.Puts(), m.Discards(), m.Outstanding()}; got != [4]int64{3, 1, 1, 1} {
+		t.Errorf("Metrics: gets, puts, discards, outstanding = %v, want %v", got, [4]int64{3, 1, 1, 1})
+	}
+}
+
+func TestWithValidation(t *testing.T) {
+	closed := make(chan int, 2)
+	p := Chain(
+		NewPool(2, Options[int]{CloseFunc: func(v int) { closed <- v }}),
+		WithValidation(func(v int) bool { return v > 0 }),
+	)
+
+	p.Put(-1)
+	p.(TxnPutter[int]).BeginPut(-2).Commit()
+	p.Put(1)
+
+	if v := p.Get(); v != 1 {
+		t.Errorf("validationPool.Get() = %d, want %d", v, 1)
+	}
+
+	p.Close().Wait()
+	if got := len(closed); got != 2 {
+		t.Errorf("validationPool: %d invalid instances closed, want %d", got, 2)
+	}
+}
+
+// minimalPool implements only the Pool interface, like a Pool from outside this package.
+type minimalPool[T any] struct {
+	c chan T
+}
+
+func (p minimalPool[T]) Get() (v T) {
+	select {
+	case v = <-p.c:
+	default:
+	}
+	return v
+}
+
+func (p minimalPool[T]) Put(v T) {
+	p.c <- v
+}
+
+func (p minimalPool[T]) Close() *sync.WaitGroup {
+	return new(sync.WaitGroup)
+}
+
+func TestChain_minimalPool(t *testing.T) {
+	var m Metrics
+	p := Chain[int](minimalPool[int]{make(chan int, 1)},
+		WithMetrics[int](&m),
+		WithValidation(func(v int) bool { return v > 0 }),
+	)
+
+	p.Put(-1) // dropped, as minimalPool can't discard.
+	p.Put(1)
+	if v := p.Get(); v != 1 {
+		t.Errorf("Get() = %d, want %d", v, 1)
+	}
+
+	// minimalPool implements none of the optional interfaces,
+	// so the forwarding falls back instead of panicking.
+	p.(DeferredPutter[int]).PutWithDeferred(2, func(int) {
+		t.Error("PutWithDeferred: onNextGet run for a dropped instance")
+	})
+
+	txn := p.(TxnPutter[int]).BeginPut(3)
+	txn.Commit()
+	if v := p.Get(); v != 3 {
+		t.Errorf("Get() after BeginPut().Commit() = %d, want %d", v, 3)
+	}
+	p.(TxnPutter[int]).BeginPut(4).Abort()
+
+	p.(Recloser[int]).RecloseBuffered(nil).Wait()
+
+	if v := p.Get(); v != 0 {
+		t.Errorf("Get() = %d, want nothing buffered", v)
+	}
+}
@@ -0,0 +1,180 @@
+package gpool
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// PriorityPool is a Pool that hands out the buffered instance
+// with the highest priority first, for example the connection with the lowest latency.
+// Priorities are assigned by the caller on PutPriority.
+// Instances of equal priority are handed out in the order they were put.
+//
+// PriorityPool is backed by a heap guarded by a mutex instead of a channel,
+// so Get and Put cost O(log n).
+type PriorityPool[T any] interface {
+	Pool[T]
+
+	// PutPriority puts an instance in the Pool with priority prio.
+	// Put is the same as PutPriority with prio 0.
+	// If the Pool is full, the instance is discarded.
+	PutPriority(instance T, prio int)
+}
+
+type prioEntry[T any] struct {
+	entry[T]
+	prio int
+	seq  uint64
+}
+
+// prioHeap implements heap.Interface as a max-heap on prio, then min-heap on seq.
+type prioHeap[T any] []prioEntry[T]
+
+func (h prioHeap[T]) Len() int { return len(h) }
+
+func (h prioHeap[T]) Less(i, j int) bool {
+	if h[i].prio != h[j].prio {
+		return h[i].prio > h[j].prio
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h prioHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *prioHeap[T]) Push(x any) { *h = append(*h, x.(prioEntry[T])) }
+
+func (h *prioHeap[T]) Pop() any {
+	old := *h
+	n := len(old) - 1
+	e := old[n]
+	old[n] = prioEntry[T]{}
+	*h = old[:n]
+	return e
+}
+
+type priorityPool[T any] struct {
+	// pool provides the NewFunc and CloseFunc logic. Its channel is unused.
+	*pool[T]
+
+	mu     sync.Mutex
+	h      prioHeap[T]
+	size   int
+	seq    uint64
+	closed bool
+}
+
+func (p *priorityPool[T]) Get() T {
+	v, _ := p.GetOK()
+	return v
+}
+
+func (p *priorityPool[T]) GetOK() (T, bool) {
+	v, ok := p.get()
+	if ok {
+		p.emit(EventGet, v, nil)
+	}
+	return v, ok
+}
+
+func (p *priorityPool[T]) get() (T, bool) {
+	for discarded := 0; ; discarded++ {
+		if p.maxValidate > 0 && discarded >= p.maxValidate {
+			return p.tryNew()
+		}
+
+		p.mu.Lock()
+		if len(p.h) == 0 {
+			p.mu.Unlock()
+			return p.tryNew()
+		}
+		e := heap.Pop(&p.h).(prioEntry[T])
+		p.mu.Unlock()
+
+		if err := p.validate(e.v); err != nil {
+			p.discardInvalid(e.v, err)
+			continue
+		}
+		if e.onNextGet != nil {
+			e.onNextGet(e.v)
+		}
+		return e.v, true
+	}
+}
+
+func (p *priorityPool[T]) push(e entry[T], prio int) {
+	p.mu.Lock()
+	if p.closed || len(p.h) >= p.size {
+		p.mu.Unlock()
+		p.maybeClose(e.v)
+		return
+	}
+	p.seq++
+	heap.Push(&p.h, prioEntry[T]{e, prio, p.seq})
+	p.mu.Unlock()
+	p.emit(EventPut, e.v, nil)
+}
+
+func (p *priorityPool[T]) PutPriority(v T, prio int) {
+	p.push(entry[T]{v: v}, prio)
+}
+
+func (p *priorityPool[T]) Put(v T) {
+	p.push(entry[T]{v: v}, 0)
+}
+
+func (p *priorityPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	p.push(entry[T]{v: v, onNextGet: onNextGet}, 0)
+}
+
+func (p *priorityPool[T]) BeginPut(v T) PutTxn {
+	return newPutTxn(v, p.Put, p.maybeClose, p.leakFunc())
+}
+
+// Close discards all instances in the Pool, like Pool.Close.
+// A Put after Close discards the instance.
+func (p *priorityPool[T]) Close() *sync.WaitGroup {
+	p.shutdown(p.maybeClose)
+	return &p.wg
+}
+
+// shutdown shadows pool.shutdown, as the instances are in the heap.
+func (p *priorityPool[T]) shutdown(discard func(T)) {
+	var zero T
+	p.emit(EventClose, zero, nil)
+
+	p.mu.Lock()
+	h := p.h
+	p.h, p.closed = nil, true
+	p.mu.Unlock()
+
+	for _, e := range h {
+		discard(e.v)
+	}
+}
+
+func (p *priorityPool[T]) RecloseBuffered(closeFunc func(T)) *sync.WaitGroup {
+	wg := p.pool.RecloseBuffered(closeFunc)
+
+	p.mu.Lock()
+	h := p.h
+	p.h = nil
+	p.mu.Unlock()
+
+	for _, e := range h {
+		p.maybeClose(e.v)
+	}
+	return wg
+}
+
+// NewPriorityPool returns a PriorityPool that can hold "size" amount of instances of T.
+// Options that tune the channel buffer of a Pool are ignored:
+// CoalesceCreates, OnDrained, OnFullFunc, PutGrace, TraceDiscards
+// and BackgroundValidateInterval. EventDrained is never sent,
+// and a Put on a full PriorityPool always discards the instance right away.
+func NewPriorityPool[T any](size int, opt Options[T]) PriorityPool[T] {
+	opt.BackgroundValidateInterval = 0
+	return &priorityPool[T]{
+		pool: newPool(0, opt),
+		size: size,
+	}
+}
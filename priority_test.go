@@ -0,0 +1,62 @@
+package gpool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPriorityPool(t *testing.T) {
+	closed := make(chan string, 2)
+	p := NewPriorityPool(4, Options[string]{
+		NewFunc:   func() string { return "new" },
+		CloseFunc: func(v string) { closed <- v },
+	})
+
+	p.PutPriority("low", -1)
+	p.Put("zero-a")
+	p.PutPriority("high", 10)
+	p.Put("zero-b")
+	p.PutPriority("overflow", 100)
+
+	if v := <-closed; v != "overflow" {
+		t.Errorf("PriorityPool.PutPriority(): discarded %q on full pool, want %q", v, "overflow")
+	}
+
+	for _, want := range []string{"high", "zero-a", "zero-b", "low", "new"} {
+		if v := p.Get(); v != want {
+			t.Errorf("PriorityPool.Get() = %q, want %q", v, want)
+		}
+	}
+
+	p.Close().Wait()
+	p.Put("closed")
+	if v := <-closed; v != "closed" {
+		t.Errorf("PriorityPool.Put(): after Close, discarded %q, want %q", v, "closed")
+	}
+}
+
+func TestPriorityPool_Validators(t *testing.T) {
+	var errs []error
+	p := NewPriorityPool(2, Options[int]{
+		Validators: []func(int) error{
+			func(v int) error {
+				if v < 0 {
+					return errors.New("negative")
+				}
+				return nil
+			},
+		},
+		ErrorFunc: func(err error) { errs = append(errs, err) },
+	})
+
+	p.PutPriority(-1, 10)
+	p.Put(1)
+
+	if v := p.Get(); v != 1 {
+		t.Errorf("PriorityPool.Get() = %d, want %d", v, 1)
+	}
+	var valErr *ValidationError
+	if len(errs) != 1 || !errors.As(errs[0], &valErr) {
+		t.Errorf("ErrorFunc: errs = %v, want one ValidationError", errs)
+	}
+}
@@ -0,0 +1,43 @@
+package gpool
+
+import (
+	"context"
+	"sync"
+)
+
+// GetScoped gets an instance from p, bound to the lifetime of ctx.
+// The returned release function puts the instance back in p.
+// If ctx is done before release is called, the instance is put back automatically.
+// Calling release more than once, or after ctx is done, is a no-op.
+//
+// Each call starts a watcher Go routine, which exits on release or when ctx is done.
+// This is cheap but not free, so prefer plain Get and Put on hot paths.
+// Callers must not use the instance after ctx is done,
+// as it may already be handed out to someone else.
+//
+// Release and the watcher may Put after p is closed,
+// which the Pools of this package handle by discarding the instance.
+// Other Pool implementations must allow a Put after Close to be used here.
+func GetScoped[T any](ctx context.Context, p Pool[T]) (instance T, release func()) {
+	v := p.Get()
+
+	released := make(chan struct{})
+	var once sync.Once
+
+	release = func() {
+		once.Do(func() {
+			close(released)
+			p.Put(v)
+		})
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			release()
+		case <-released:
+		}
+	}()
+
+	return v, release
+}
@@ -0,0 +1,70 @@
+package gpool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetScoped(t *testing.T) {
+	closed := make(chan *int, 2)
+	p := NewPool(1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(v *int) { closed <- v },
+	})
+
+	t.Run("release", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		v, release := GetScoped(ctx, p)
+		release()
+		release()
+		cancel()
+
+		select {
+		case <-closed:
+			t.Fatal("GetScoped(): instance put more than once")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		if w := p.Get(); w != v {
+			t.Error("GetScoped(): released instance not in pool")
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		v, _ := GetScoped(ctx, p)
+		cancel()
+
+		deadline := time.After(time.Second)
+		for {
+			w := p.Get()
+			if w == v {
+				return
+			}
+			select {
+			case <-deadline:
+				t.Fatal("GetScoped(): instance not put back after context done")
+			case <-time.After(time.Millisecond):
+			}
+		}
+	})
+}
+
+func TestGetScoped_afterClose(t *testing.T) {
+	p := NewPool(1, Options[*int]{
+		NewFunc: func() *int { return new(int) },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	GetScoped(ctx, p)
+
+	p.Close().Wait()
+	cancel()
+
+	// A panic in the watcher would crash the test binary.
+	time.Sleep(10 * time.Millisecond)
+}
@@ -0,0 +1,114 @@
+package gpool
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// owned is an instance that records which Go routine holds it.
+type owned struct {
+	owner int32
+}
+
+func (o *owned) Reset()           {}
+func (o *owned) Healthy() bool    { return true }
+func (o *owned) Validate() error  { return nil }
+func (o *owned) Acquire() error   { return nil }
+func (o *owned) ReleaseResource() {}
+
+// TestPool_soleOwnership checks that no two Go routines ever hold the same instance.
+// Run with -race to also catch unsynchronised access.
+func TestPool_soleOwnership(t *testing.T) {
+	const (
+		size       = 8
+		goroutines = 32
+		rounds     = 500
+	)
+
+	opt := Options[*owned]{
+		NewFunc:   func() *owned { return new(owned) },
+		CloseFunc: func(*owned) {},
+	}
+	evict := opt
+	evict.OnFullFunc = func(*owned) FullAction { return FullEvict }
+	coalesce := opt
+	coalesce.CoalesceCreates = true
+	validators := opt
+	validators.Validators = []func(*owned) error{func(*owned) error { return nil }}
+	background := validators
+	background.BackgroundValidateInterval = time.Millisecond
+	budget := opt
+	budget.Budget = NewBudget(goroutines + size) // Never exhausted, so Get never blocks on it.
+	grace := opt
+	grace.PutGrace = time.Millisecond
+
+	tests := []struct {
+		name string
+		pool func() Pool[*owned]
+	}{
+		{"pool", func() Pool[*owned] { return NewPool(size, opt) }},
+		{"evict", func() Pool[*owned] { return NewPool(size, evict) }},
+		{"coalesce", func() Pool[*owned] { return NewPool(size, coalesce) }},
+		{"validators", func() Pool[*owned] { return NewPool(size, validators) }},
+		{"background validate", func() Pool[*owned] { return NewPool(size, background) }},
+		{"budget", func() Pool[*owned] { return NewPool(size, budget) }},
+		{"put grace", func() Pool[*owned] { return NewPool(size, grace) }},
+		{"resetter", func() Pool[*owned] { return NewResetterPool(size, opt) }},
+		{"health checked", func() Pool[*owned] { return NewHealthCheckedPool(size, opt) }},
+		{"validated", func() Pool[*owned] { return NewValidatedPool(size, opt) }},
+		{"acquirer", func() Pool[*owned] { return NewAcquirerPool(size, opt) }},
+		{"versioned", func() Pool[*owned] { return NewVersionedPool(size, opt) }},
+		{"context", func() Pool[*owned] { return NewContextPool(size, opt) }},
+		{"priority", func() Pool[*owned] { return NewPriorityPool(size, opt) }},
+		{"supervised", func() Pool[*owned] {
+			return NewSupervisedPool(func() Pool[*owned] { return NewPool(size, opt) })
+		}},
+		{"middleware", func() Pool[*owned] {
+			return Chain(NewPool(size, opt),
+				WithMetrics[*owned](new(Metrics)),
+				WithLogging[*owned](func(string, ...any) {}),
+				WithValidation(func(*owned) bool { return true }),
+			)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.pool()
+			d := p.(DeferredPutter[*owned])
+
+			var wg sync.WaitGroup
+			for g := int32(1); g <= goroutines; g++ {
+				wg.Add(1)
+				go func(id int32) {
+					defer wg.Done()
+
+					for i := 0; i < rounds; i++ {
+						v := p.Get()
+						if !atomic.CompareAndSwapInt32(&v.owner, 0, id) {
+							t.Errorf("Go routine %d got instance held by %d", id, atomic.LoadInt32(&v.owner))
+							return
+						}
+						runtime.Gosched()
+						if !atomic.CompareAndSwapInt32(&v.owner, id, 0) {
+							t.Errorf("Go routine %d lost instance to %d", id, atomic.LoadInt32(&v.owner))
+							return
+						}
+
+						if i%2 == 0 {
+							p.Put(v)
+						} else {
+							d.PutWithDeferred(v, func(*owned) {})
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			p.Close().Wait()
+		})
+	}
+}
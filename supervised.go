@@ -0,0 +1,196 @@
+package gpool
+
+import "sync"
+
+// SupervisedPool is a Pool whose underlying Pool can be replaced at runtime,
+// while consumers keep using the same SupervisedPool.
+type SupervisedPool[T any] interface {
+	Pool[T]
+
+	// Restart replaces the underlying Pool with a fresh one from the factory
+	// and closes the old one once its calls in progress returned.
+	// The returned WaitGroup is done when the old Pool is closed.
+	// Instances that are checked out during Restart are put in the new Pool
+	// when they are returned. Callers that can't reuse those
+	// after a Restart must discard them instead of calling Put.
+	// After Close, Restart keeps the closed Pool in place:
+	// it closes the fresh Pool from the factory right away and returns its WaitGroup.
+	Restart() *sync.WaitGroup
+}
+
+type supervisedPool[T any] struct {
+	factory func() Pool[T]
+
+	// mu guards current and closed. It is never held
+	// while calling the underlying Pool, as its calls may block.
+	mu      sync.Mutex
+	current *generation[T]
+	closed  bool
+}
+
+// generation is an underlying Pool with the amount of calls in progress,
+// so Restart never closes a Pool that is still in use.
+type generation[T any] struct {
+	Pool[T]
+	users   int
+	retired bool
+	idle    chan struct{} // Closed once retired and without users.
+}
+
+func newGeneration[T any](p Pool[T]) *generation[T] {
+	return &generation[T]{
+		Pool: p,
+		idle: make(chan struct{}),
+	}
+}
+
+// acquire returns the current generation and counts the caller as a user.
+// The caller must call release when done.
+func (p *supervisedPool[T]) acquire() *generation[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	g := p.current
+	g.users++
+	return g
+}
+
+func (p *supervisedPool[T]) release(g *generation[T]) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	g.users--
+	g.signalIdle()
+}
+
+// retire marks g as replaced or closed. p.mu must be held.
+func (g *generation[T]) retire() {
+	g.retired = true
+	g.signalIdle()
+}
+
+// signalIdle closes idle if g is retired and without users. p.mu must be held.
+// After Close, calls keep using the retired generation, so it may get idle more than once.
+func (g *generation[T]) signalIdle() {
+	if !g.retired || g.users > 0 {
+		return
+	}
+	select {
+	case <-g.idle:
+	default:
+		close(g.idle)
+	}
+}
+
+// closeWhenIdle closes the Pool of a retired generation
+// once its calls in progress returned.
+func (g *generation[T]) closeWhenIdle() *sync.WaitGroup {
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-g.idle
+		g.Close().Wait()
+	}()
+	return wg
+}
+
+func (p *supervisedPool[T]) Get() T {
+	g := p.acquire()
+	defer p.release(g)
+	return g.Get()
+}
+
+func (p *supervisedPool[T]) GetOK() (T, bool) {
+	g := p.acquire()
+	defer p.release(g)
+	return GetOK(g.Pool)
+}
+
+func (p *supervisedPool[T]) Put(v T) {
+	g := p.acquire()
+	defer p.release(g)
+	g.Put(v)
+}
+
+func (p *supervisedPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	g := p.acquire()
+	defer p.release(g)
+	PutWithDeferred(g.Pool, v, onNextGet)
+}
+
+// BeginPut returns a PutTxn that acts on the underlying Pool
+// at the time of Commit or Abort, which may be a Pool from a later Restart.
+// Such a PutTxn is not reported to ErrorFunc when it leaks.
+func (p *supervisedPool[T]) BeginPut(v T) PutTxn {
+	return newPutTxn(v, p.Put, p.discard, nil)
+}
+
+func (p *supervisedPool[T]) discard(v T) {
+	g := p.acquire()
+	defer p.release(g)
+	discard(g.Pool, v)
+}
+
+// Close closes the underlying Pool once its calls in progress returned.
+func (p *supervisedPool[T]) Close() *sync.WaitGroup {
+	p.mu.Lock()
+	p.closed = true
+	g := p.current
+	g.retire()
+	p.mu.Unlock()
+
+	return g.closeWhenIdle()
+}
+
+// CloseWithGroup closes the underlying Pool through grp, if it has no calls in progress.
+// Otherwise a single grp.Go call waits for them and then for Close,
+// as submitting from a running function could deadlock a group with a limit.
+func (p *supervisedPool[T]) CloseWithGroup(grp Grouper) {
+	p.mu.Lock()
+	p.closed = true
+	g := p.current
+	g.retire()
+	idle := g.users == 0
+	p.mu.Unlock()
+
+	if idle {
+		CloseWithGroup(g.Pool, grp)
+		return
+	}
+	grp.Go(func() error {
+		g.closeWhenIdle().Wait()
+		return nil
+	})
+}
+
+// RecloseBuffered acts on the current underlying Pool.
+// A Pool from a later Restart uses the CloseFunc of the factory again.
+func (p *supervisedPool[T]) RecloseBuffered(closeFunc func(T)) *sync.WaitGroup {
+	g := p.acquire()
+	defer p.release(g)
+	return RecloseBuffered(g.Pool, closeFunc)
+}
+
+func (p *supervisedPool[T]) Restart() *sync.WaitGroup {
+	fresh := p.factory()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return fresh.Close()
+	}
+	old := p.current
+	p.current = newGeneration(fresh)
+	old.retire()
+	p.mu.Unlock()
+
+	return old.closeWhenIdle()
+}
+
+// NewSupervisedPool returns a SupervisedPool,
+// which uses factory to create the underlying Pool now and on each Restart.
+func NewSupervisedPool[T any](factory func() Pool[T]) SupervisedPool[T] {
+	return &supervisedPool[T]{
+		factory: factory,
+		current: newGeneration(factory()),
+	}
+}
@@ -0,0 +1,144 @@
+package gpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSupervisedPool(t *testing.T) {
+	var mu sync.Mutex
+	closed := make(map[*int]bool)
+
+	p := NewSupervisedPool(func() Pool[*int] {
+		return NewPool(10, Options[*int]{
+			NewFunc: func() *int { return new(int) },
+			CloseFunc: func(v *int) {
+				mu.Lock()
+				closed[v] = true
+				mu.Unlock()
+			},
+		})
+	})
+
+	buffered := p.Get()
+	inFlight := p.Get()
+	p.Put(buffered)
+
+	p.Restart().Wait()
+
+	if !closed[buffered] {
+		t.Error("SupervisedPool.Restart(): buffered instance not closed")
+	}
+	if w := p.Get(); w == buffered {
+		t.Error("SupervisedPool.Restart(): Get returned instance from old pool")
+	}
+
+	p.Put(inFlight)
+	if w := p.Get(); w != inFlight {
+		t.Error("SupervisedPool.Restart(): in-flight instance not put in new pool")
+	}
+}
+
+func TestSupervisedPool_concurrent(t *testing.T) {
+	p := NewSupervisedPool(func() Pool[*int] {
+		return NewPool(10, Options[*int]{
+			NewFunc: func() *int { return new(int) },
+		})
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p.Put(p.Get())
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		p.Restart()
+	}
+	wg.Wait()
+	p.Close().Wait()
+}
+
+func TestSupervisedPool_RestartAfterClose(t *testing.T) {
+	var created int
+	p := NewSupervisedPool(func() Pool[*int] {
+		created++
+		return NewPool(1, Options[*int]{})
+	})
+
+	p.Close().Wait()
+	p.Restart().Wait()
+
+	if created != 2 {
+		t.Errorf("factory called %d times, want %d", created, 2)
+	}
+}
+
+func TestSupervisedPool_RestartBlockedPut(t *testing.T) {
+	var pools []Pool[*int]
+	p := NewSupervisedPool(func() Pool[*int] {
+		np := NewPool(1, Options[*int]{
+			NewFunc:    func() *int { return new(int) },
+			OnFullFunc: func(*int) FullAction { return FullBlock },
+		})
+		pools = append(pools, np)
+		return np
+	})
+
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+
+	putDone := make(chan struct{})
+	go func() {
+		p.Put(b)
+		close(putDone)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	restarted := make(chan *sync.WaitGroup)
+	go func() { restarted <- p.Restart() }()
+
+	var wg *sync.WaitGroup
+	select {
+	case wg = <-restarted:
+	case <-time.After(time.Second):
+		t.Fatal("SupervisedPool.Restart(): blocked by a Put in progress")
+	}
+
+	got := make(chan struct{})
+	go func() {
+		p.Get()
+		close(got)
+	}()
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("SupervisedPool.Get(): blocked after Restart")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("SupervisedPool.Restart(): closed the old Pool while a Put was in progress")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Make room in the old Pool, so the blocked Put returns.
+	pools[0].Get()
+	<-putDone
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("SupervisedPool.Restart(): old Pool not closed after the Put returned")
+	}
+}
@@ -0,0 +1,78 @@
+package gpool
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TaggedPool routes operations to one of a fixed set of sub-Pools, selected by tag.
+// For example, one sub-Pool for connections to a primary and one for a read replica.
+// The set of tags is fixed at construction, so routing needs no locking.
+// Using a tag that was not passed to NewTaggedPool panics.
+type TaggedPool[T any] interface {
+	// Get an instance from the sub-Pool for tag, like Pool.Get.
+	Get(tag string) T
+
+	// Put an instance in the sub-Pool for tag, like Pool.Put.
+	Put(tag string, instance T)
+
+	// Close all sub-Pools, like Pool.Close.
+	// The returned WaitGroup is done when all sub-Pools are.
+	Close() *sync.WaitGroup
+}
+
+type taggedPool[T any] map[string]Pool[T]
+
+func (p taggedPool[T]) pool(tag string) Pool[T] {
+	sub, ok := p[tag]
+	if !ok {
+		panic(fmt.Sprintf("gpool: unknown tag %q", tag))
+	}
+	return sub
+}
+
+func (p taggedPool[T]) Get(tag string) T {
+	return p.pool(tag).Get()
+}
+
+func (p taggedPool[T]) Put(tag string, v T) {
+	p.pool(tag).Put(v)
+}
+
+func (p taggedPool[T]) Close() *sync.WaitGroup {
+	wgs := make([]*sync.WaitGroup, 0, len(p))
+	for _, sub := range p {
+		wgs = append(wgs, sub.Close())
+	}
+	return joinWaitGroups(wgs...)
+}
+
+// NewTaggedPool returns a TaggedPool with a sub-Pool for each tag.
+// Each sub-Pool can hold "sizePer" amount of instances of T and uses opt.
+// NewTaggedPool panics if a tag is passed more than once.
+func NewTaggedPool[T any](tags []string, sizePer int, opt Options[T]) TaggedPool[T] {
+	p := make(taggedPool[T], len(tags))
+	for _, tag := range tags {
+		if _, ok := p[tag]; ok {
+			p.Close()
+			panic(fmt.Sprintf("gpool: duplicate tag %q", tag))
+		}
+		p[tag] = NewPool(sizePer, opt)
+	}
+	return p
+}
+
+// joinWaitGroups returns a WaitGroup that is done when all wgs are.
+func joinWaitGroups(wgs ...*sync.WaitGroup) *sync.WaitGroup {
+	joined := new(sync.WaitGroup)
+	joined.Add(len(wgs))
+
+	for _, wg := range wgs {
+		go func(wg *sync.WaitGroup) {
+			defer joined.Done()
+			wg.Wait()
+		}(wg)
+	}
+
+	return joined
+}
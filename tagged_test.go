@@ -0,0 +1,53 @@
+package gpool
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestTaggedPool(t *testing.T) {
+	var closed int32
+	p := NewTaggedPool([]string{"primary", "replica"}, 1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(*int) { atomic.AddInt32(&closed, 1) },
+	})
+
+	primary := p.Get("primary")
+	p.Put("primary", primary)
+
+	if v := p.Get("replica"); v == primary {
+		t.Error("TaggedPool.Get(): instance shared between tags")
+	} else {
+		p.Put("replica", v)
+	}
+	if v := p.Get("primary"); v != primary {
+		t.Error("TaggedPool.Get(): instance not reused within tag")
+	} else {
+		p.Put("primary", v)
+	}
+
+	p.Close().Wait()
+	if closed != 2 {
+		t.Errorf("TaggedPool.Close(): closed %d instances, want %d", closed, 2)
+	}
+}
+
+func TestTaggedPool_unknownTag(t *testing.T) {
+	p := NewTaggedPool([]string{"a"}, 1, Options[int]{})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("TaggedPool.Get(): no panic for unknown tag")
+		}
+	}()
+	p.Get("b")
+}
+
+func TestNewTaggedPool_duplicateTag(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewTaggedPool(): no panic for duplicate tag")
+		}
+	}()
+	NewTaggedPool([]string{"a", "b", "a"}, 1, Options[int]{})
+}
@@ -0,0 +1,100 @@
+package gpool
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+)
+
+// ErrPutTxnLeaked is passed to Options.ErrorFunc when a PutTxn
+// is garbage collected without Commit or Abort being called.
+// The instance it held is lost, without CloseFunc being called.
+var ErrPutTxnLeaked = errors.New("gpool: PutTxn garbage collected without Commit or Abort")
+
+// TxnPutter is a Pool that supports two-phase Puts.
+// See Pool for how to check for it.
+type TxnPutter[T any] interface {
+	// BeginPut starts returning an instance to the Pool,
+	// leaving the decision to the returned PutTxn.
+	// PutTxn.Commit puts the instance like Put
+	// and PutTxn.Abort discards it through CloseFunc.
+	BeginPut(instance T) PutTxn
+}
+
+// BeginPut calls p.BeginPut if p is a TxnPutter.
+// Otherwise it returns a PutTxn whose Commit calls p.Put
+// and whose Abort drops the instance, like discard.
+func BeginPut[T any](p Pool[T], v T) PutTxn {
+	if t, ok := p.(TxnPutter[T]); ok {
+		return t.BeginPut(v)
+	}
+	return newPutTxn(v, p.Put, func(T) {}, nil)
+}
+
+// discard discards v through the CloseFunc of p, if p is a TxnPutter.
+// Otherwise v is dropped, as p offers no other way to discard it.
+func discard[T any](p Pool[T], v T) {
+	if t, ok := p.(TxnPutter[T]); ok {
+		t.BeginPut(v).Abort()
+	}
+}
+
+// PutTxn is a pending Put, returned by TxnPutter.BeginPut.
+// It lets the caller hold on to the decision of pooling or discarding an instance,
+// for example while checking if a connection is still valid.
+// Exactly one of Commit or Abort must be called; later calls are no-ops.
+// A PutTxn that is never finished leaks its instance.
+type PutTxn interface {
+	// Commit puts the instance in the Pool, like Put.
+	Commit()
+
+	// Abort discards the instance, calling CloseFunc if it is not nil.
+	Abort()
+}
+
+type putTxn[T any] struct {
+	v       T
+	put     func(T)
+	discard func(T)
+	done    int32
+}
+
+// newPutTxn returns a PutTxn for v.
+// If errFunc is not nil, it receives ErrPutTxnLeaked from a finalizer
+// when the PutTxn is never finished.
+func newPutTxn[T any](v T, put, discard func(T), errFunc func(error)) PutTxn {
+	t := &putTxn[T]{
+		v:       v,
+		put:     put,
+		discard: discard,
+	}
+
+	if errFunc != nil {
+		runtime.SetFinalizer(t, func(*putTxn[T]) {
+			errFunc(ErrPutTxnLeaked)
+		})
+	}
+
+	return t
+}
+
+// finish reports if this is the first call to Commit or Abort.
+func (t *putTxn[T]) finish() bool {
+	if !atomic.CompareAndSwapInt32(&t.done, 0, 1) {
+		return false
+	}
+	runtime.SetFinalizer(t, nil)
+	return true
+}
+
+func (t *putTxn[T]) Commit() {
+	if t.finish() {
+		t.put(t.v)
+	}
+}
+
+func (t *putTxn[T]) Abort() {
+	if t.finish() {
+		t.discard(t.v)
+	}
+}
@@ -0,0 +1,79 @@
+package gpool
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPool_BeginPut(t *testing.T) {
+	closed := make(chan *int, 2)
+	p := NewPool(1, Options[*int]{
+		NewFunc:   func() *int { return new(int) },
+		CloseFunc: func(v *int) { closed <- v },
+	})
+	txnPutter := p.(TxnPutter[*int])
+
+	t.Run("commit", func(t *testing.T) {
+		v := p.Get()
+		txn := txnPutter.BeginPut(v)
+		txn.Commit()
+		txn.Abort()
+
+		if w := p.Get(); w != v {
+			t.Error("PutTxn.Commit(): instance not pooled")
+		}
+		select {
+		case <-closed:
+			t.Error("PutTxn.Abort(): called after Commit closed the instance")
+		default:
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		v := p.Get()
+		txnPutter.BeginPut(v).Abort()
+		p.Close().Wait()
+
+		if w := <-closed; w != v {
+			t.Error("PutTxn.Abort(): instance not closed")
+		}
+	})
+}
+
+func TestPool_BeginPut_leaked(t *testing.T) {
+	errs := make(chan error, 1)
+	p := NewPool(1, Options[*int]{
+		ErrorFunc: func(err error) { errs <- err },
+	})
+
+	p.(TxnPutter[*int]).BeginPut(new(int))
+
+	deadline := time.After(time.Second)
+	for {
+		runtime.GC()
+
+		select {
+		case err := <-errs:
+			if err != ErrPutTxnLeaked {
+				t.Errorf("ErrorFunc: err = %v, want %v", err, ErrPutTxnLeaked)
+			}
+			return
+		case <-deadline:
+			t.Fatal("ErrorFunc not called for leaked PutTxn")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestPool_leakFunc(t *testing.T) {
+	if newPool(1, Options[int]{}).leakFunc() != nil {
+		t.Error("leakFunc() not nil without ErrorFunc or Events")
+	}
+	if newPool(1, Options[int]{ErrorFunc: func(error) {}}).leakFunc() == nil {
+		t.Error("leakFunc() nil with ErrorFunc")
+	}
+	if newPool(1, Options[int]{Events: make(chan Event[int])}).leakFunc() == nil {
+		t.Error("leakFunc() nil with Events")
+	}
+}
@@ -0,0 +1,135 @@
+package gpool
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrStaleToken is returned by VersionedPool.PutToken when the Token
+// doesn't match the current checkout of the instance.
+var ErrStaleToken = errors.New("gpool: stale token")
+
+// Token identifies one checkout of an instance from a VersionedPool.
+// It is issued by GetToken and used up by the first PutToken that accepts it,
+// or invalidated when the instance is returned by any other Put.
+// The zero Token is never valid.
+type Token struct {
+	version uint64
+}
+
+// VersionedPool is a Pool that can tag each handed out instance with a version.
+// An instance is only accepted back by PutVersioned if the caller presents
+// the version it was last issued with.
+// This detects a caller returning an instance that has since been
+// re-issued to someone else, for example after a double Put.
+//
+// Instances are tracked by value, so T must be comparable.
+// Pointer types are the intended use.
+// Unlike Pool, tracking is guarded by a mutex.
+//
+// An issued version, or Token, is only forgotten when the instance is returned
+// by one of the Puts. An instance from GetVersioned or GetToken that the caller
+// drops, leaks or discards itself stays registered, and a pointer T stays reachable,
+// for the life of the VersionedPool. Callers that don't return an instance
+// must not take it with GetVersioned or GetToken.
+type VersionedPool[T comparable] interface {
+	Pool[T]
+
+	// GetVersioned gets an instance from the Pool, like Get,
+	// and returns the version it is issued with.
+	// The zero value of T is not tracked and always comes with version 0.
+	GetVersioned() (instance T, version uint64)
+
+	// PutVersioned puts the instance in the Pool, like Put,
+	// if version is the one it was last issued with.
+	// It returns false and drops the instance if the version is stale:
+	// the instance was already returned or re-issued.
+	// A plain Put, PutWithDeferred or BeginPut of the instance invalidates its version as well.
+	PutVersioned(version uint64, instance T) bool
+
+	// GetToken is like GetVersioned, returning an opaque Token.
+	GetToken() (instance T, token Token)
+
+	// PutToken is like PutVersioned, returning ErrStaleToken
+	// instead of false when the instance is not accepted.
+	PutToken(token Token, instance T) error
+}
+
+type versionedPool[T comparable] struct {
+	*pool[T]
+
+	mu     sync.Mutex
+	last   uint64
+	issued map[T]uint64
+}
+
+func (p *versionedPool[T]) GetVersioned() (T, uint64) {
+	v := p.pool.Get()
+
+	var zero T
+	if v == zero {
+		return v, 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.last++
+	p.issued[v] = p.last
+
+	return v, p.last
+}
+
+func (p *versionedPool[T]) PutVersioned(version uint64, v T) bool {
+	p.mu.Lock()
+	if current, ok := p.issued[v]; !ok || version == 0 || current != version {
+		p.mu.Unlock()
+		return false
+	}
+	delete(p.issued, v)
+	p.mu.Unlock()
+
+	p.pool.Put(v)
+	return true
+}
+
+func (p *versionedPool[T]) GetToken() (T, Token) {
+	v, version := p.GetVersioned()
+	return v, Token{version}
+}
+
+func (p *versionedPool[T]) PutToken(token Token, v T) error {
+	if !p.PutVersioned(token.version, v) {
+		return ErrStaleToken
+	}
+	return nil
+}
+
+func (p *versionedPool[T]) Put(v T) {
+	p.forget(v)
+	p.pool.Put(v)
+}
+
+func (p *versionedPool[T]) PutWithDeferred(v T, onNextGet func(T)) {
+	p.forget(v)
+	p.pool.PutWithDeferred(v, onNextGet)
+}
+
+func (p *versionedPool[T]) BeginPut(v T) PutTxn {
+	p.forget(v)
+	return p.pool.BeginPut(v)
+}
+
+func (p *versionedPool[T]) forget(v T) {
+	p.mu.Lock()
+	delete(p.issued, v)
+	p.mu.Unlock()
+}
+
+// NewVersionedPool returns a VersionedPool that can hold "size" amount of instances of T.
+func NewVersionedPool[T comparable](size int, opt Options[T]) VersionedPool[T] {
+	return &versionedPool[T]{
+		pool:   newPool(size, opt),
+		issued: make(map[T]uint64),
+	}
+}
@@ -0,0 +1,65 @@
+package gpool
+
+import "testing"
+
+func TestVersionedPool(t *testing.T) {
+	p := NewVersionedPool(1, Options[*int]{
+		NewFunc: func() *int { return new(int) },
+	})
+
+	v, ver := p.GetVersioned()
+	if ver == 0 {
+		t.Fatal("VersionedPool.GetVersioned(): version 0 for a non-zero instance")
+	}
+	if !p.PutVersioned(ver, v) {
+		t.Fatal("VersionedPool.PutVersioned(): current version rejected")
+	}
+	if p.PutVersioned(ver, v) {
+		t.Error("VersionedPool.PutVersioned(): double Put accepted")
+	}
+
+	w, wver := p.GetVersioned()
+	if w != v {
+		t.Fatal("VersionedPool.GetVersioned(): instance not reused")
+	}
+	if wver == ver {
+		t.Fatalf("VersionedPool.GetVersioned(): re-issued with the same version %d", ver)
+	}
+	if p.PutVersioned(ver, w) {
+		t.Error("VersionedPool.PutVersioned(): stale version accepted")
+	}
+
+	p.Put(w)
+	if p.PutVersioned(wver, w) {
+		t.Error("VersionedPool.PutVersioned(): version still valid after plain Put")
+	}
+}
+
+func TestVersionedPool_zero(t *testing.T) {
+	p := NewVersionedPool(1, Options[*int]{})
+
+	v, ver := p.GetVersioned()
+	if v != nil || ver != 0 {
+		t.Fatalf("VersionedPool.GetVersioned() = %v, %d, want nil, 0", v, ver)
+	}
+	if p.PutVersioned(ver, v) {
+		t.Error("VersionedPool.PutVersioned(): zero instance accepted")
+	}
+}
+
+func TestVersionedPool_Token(t *testing.T) {
+	p := NewVersionedPool(1, Options[*int]{
+		NewFunc: func() *int { return new(int) },
+	})
+
+	v, token := p.GetToken()
+	if err := p.PutToken(token, v); err != nil {
+		t.Fatalf("VersionedPool.PutToken() err = %v", err)
+	}
+	if err := p.PutToken(token, v); err != ErrStaleToken {
+		t.Errorf("VersionedPool.PutToken() err = %v, want %v", err, ErrStaleToken)
+	}
+	if err := p.PutToken(Token{}, v); err != ErrStaleToken {
+		t.Errorf("VersionedPool.PutToken() with zero Token err = %v, want %v", err, ErrStaleToken)
+	}
+}
@@ -0,0 +1,47 @@
+package gpool
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// zeroFields returns the indices of the pointer-like fields of the struct T points to,
+// skipping fields tagged `gpool:"keep"`.
+// It returns nil if T is not a pointer to a struct.
+func zeroFields[T any]() []int {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	st := t.Elem()
+	fields := []int{}
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if f.Tag.Get("gpool") == "keep" {
+			continue
+		}
+
+		switch f.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Chan,
+			reflect.Func, reflect.Interface, reflect.UnsafePointer:
+			fields = append(fields, i)
+		}
+	}
+	return fields
+}
+
+// zeroOut sets fields of the struct v points to to their zero value.
+// Unexported fields are set through their address, as reflect doesn't allow it otherwise.
+func zeroOut(v any, fields []int) {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return
+	}
+
+	s := rv.Elem()
+	for _, i := range fields {
+		f := s.Field(i)
+		reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem().Set(reflect.Zero(f.Type()))
+	}
+}
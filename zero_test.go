@@ -0,0 +1,56 @@
+package gpool
+
+import (
+	"bytes"
+	"testing"
+)
+
+type retainer struct {
+	Name   string
+	Graph  *[1 << 10]byte
+	buf    []byte `gpool:"keep"`
+	lookup map[string]int
+	inner  bytes.Buffer
+}
+
+func (r *retainer) Reset() {
+	r.buf = r.buf[:0]
+	// Graph and lookup are forgotten.
+}
+
+func TestZeroOnReset(t *testing.T) {
+	p := NewResetterPool(1, Options[*retainer]{
+		ZeroOnReset: true,
+	})
+
+	r := &retainer{
+		Name:   "name",
+		Graph:  new([1 << 10]byte),
+		buf:    make([]byte, 3, 64),
+		lookup: map[string]int{"a": 1},
+	}
+	r.inner.WriteString("hello")
+	p.Put(r)
+
+	if r = p.Get(); r.Graph != nil || r.lookup != nil {
+		t.Errorf("ZeroOnReset: pointer fields not zeroed: %+v", r)
+	}
+	if cap(r.buf) != 64 {
+		t.Errorf("ZeroOnReset: kept field zeroed, cap = %d", cap(r.buf))
+	}
+	if r.Name != "name" || r.inner.Len() != 5 {
+		t.Errorf("ZeroOnReset: non-pointer fields changed: %+v", r)
+	}
+}
+
+func Test_zeroFields(t *testing.T) {
+	if f := zeroFields[*bytes.Buffer](); len(f) == 0 {
+		t.Error("zeroFields[*bytes.Buffer]() returned no fields")
+	}
+	if f := zeroFields[bytes.Buffer](); f != nil {
+		t.Errorf("zeroFields[bytes.Buffer]() = %v, want nil", f)
+	}
+	if f := zeroFields[Resetter](); f != nil {
+		t.Errorf("zeroFields[Resetter]() = %v, want nil", f)
+	}
+}